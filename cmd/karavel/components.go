@@ -0,0 +1,209 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/karavel-io/cli/pkg/action"
+
+	"github.com/spf13/cobra"
+)
+
+func NewComponentsCommand() *cobra.Command {
+	var cpath string
+	var ver string
+	var unstable bool
+
+	params := func() (action.ComponentsParams, error) {
+		p := action.ComponentsParams{
+			KaravelVersion: strings.TrimPrefix(ver, "v"),
+			Unstable:       unstable,
+		}
+
+		if p.KaravelVersion == "" {
+			c, err := resolveConfigPath(cpath)
+			if err != nil {
+				return p, err
+			}
+			p.ConfigPath = c
+		}
+
+		return p, nil
+	}
+
+	cmd := &cobra.Command{
+		Use:     "components",
+		Aliases: []string{"component"},
+		Short:   "Browse the catalogue of available Karavel components",
+		Long: fmt.Sprintf(`
+Browse the catalogue of components available for a Karavel Container Platform release.
+
+The release is read from the config file (defaults to '%s' in the current directory),
+unless one is explicitly selected with --version.
+`, DefaultFileName),
+	}
+
+	cmd.PersistentFlags().StringVarP(&cpath, "file", "f", DefaultFileName, "Specify an alternate config file, or a directory whose *.hcl files are merged")
+	cmd.PersistentFlags().StringVarP(&ver, "version", "v", "", "Karavel Container Platform version to browse, instead of the one in the config file")
+	cmd.PersistentFlags().BoolVar(&unstable, "unstable", false, "Include components from the unstable repository")
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the available components",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := params()
+			if err != nil {
+				return err
+			}
+
+			entries, err := action.ListComponents(cmd.Context(), p)
+			if err != nil {
+				return err
+			}
+
+			return printCatalogue(cmd.OutOrStdout(), entries)
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "search QUERY",
+		Short: "Search components by name, description or keyword",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := params()
+			if err != nil {
+				return err
+			}
+
+			entries, err := action.SearchComponents(cmd.Context(), p, args[0])
+			if err != nil {
+				return err
+			}
+
+			return printCatalogue(cmd.OutOrStdout(), entries)
+		},
+	})
+
+	var chartVer string
+	var readme bool
+	var values bool
+	show := &cobra.Command{
+		Use:   "show NAME",
+		Short: "Show the details, default values and README of a component",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := params()
+			if err != nil {
+				return err
+			}
+
+			details, err := action.ShowComponent(cmd.Context(), p, args[0], chartVer)
+			if err != nil {
+				return err
+			}
+
+			// print everything unless a specific section was requested
+			all := !readme && !values
+			return printComponentDetails(cmd.OutOrStdout(), details, all || values, all || readme)
+		},
+	}
+	show.Flags().StringVar(&chartVer, "chart-version", "", "Component version to show. Defaults to the latest one")
+	show.Flags().BoolVar(&values, "values", false, "Only print the default values")
+	show.Flags().BoolVar(&readme, "readme", false, "Only print the README")
+	cmd.AddCommand(show)
+
+	return cmd
+}
+
+func printCatalogue(out io.Writer, entries []action.CatalogueEntry) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tVERSION\tFLAGS\tDEPENDENCIES\tINTEGRATIONS\tDESCRIPTION")
+	for _, e := range entries {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Name, e.Version, orNone(entryFlags(e)), orNone(e.Dependencies), orNone(integrationNames(e)), e.Description)
+	}
+	return w.Flush()
+}
+
+func printComponentDetails(out io.Writer, d action.ComponentDetails, values bool, readme bool) error {
+	if values && readme {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(w, "Name:\t%s\n", d.Name)
+		_, _ = fmt.Fprintf(w, "Version:\t%s\n", d.Version)
+		_, _ = fmt.Fprintf(w, "Description:\t%s\n", d.Description)
+		_, _ = fmt.Fprintf(w, "Flags:\t%s\n", orNone(entryFlags(d.CatalogueEntry)))
+		_, _ = fmt.Fprintf(w, "Dependencies:\t%s\n", orNone(d.Dependencies))
+		_, _ = fmt.Fprintln(w, "Integrations:")
+		for _, integ := range integrationNames(d.CatalogueEntry) {
+			_, _ = fmt.Fprintf(w, "  %s\trequires %s\n", integ, orNone(d.Integrations[integ]))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if values {
+		if readme {
+			_, _ = fmt.Fprintln(out, "\n--- Default values ---")
+		}
+		_, _ = fmt.Fprintln(out, strings.TrimSpace(d.Values))
+	}
+
+	if readme {
+		if values {
+			_, _ = fmt.Fprintln(out, "\n--- README ---")
+		}
+		_, err := fmt.Fprintln(out, strings.TrimSpace(d.Readme))
+		return err
+	}
+
+	return nil
+}
+
+func entryFlags(e action.CatalogueEntry) []string {
+	var flags []string
+	if e.Bootstrap {
+		flags = append(flags, "bootstrap")
+	}
+	if e.Singleton {
+		flags = append(flags, "singleton")
+	}
+	if e.Unstable {
+		flags = append(flags, "unstable")
+	}
+	return flags
+}
+
+func integrationNames(e action.CatalogueEntry) []string {
+	names := make([]string, 0, len(e.Integrations))
+	for integ := range e.Integrations {
+		names = append(names, integ)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func orNone(ss []string) string {
+	if len(ss) == 0 {
+		return "-"
+	}
+	return strings.Join(ss, ",")
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/karavel-io/cli/pkg/action"
+	"github.com/karavel-io/cli/pkg/config"
+
+	"github.com/spf13/cobra"
+)
+
+const DefaultFileName = "karavel.hcl"
+
+// alternateFileNames are looked up in order when the default config file does not exist
+var alternateFileNames = []string{"karavel.json", "karavel.yaml", "karavel.yml"}
+
+func NewConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the Karavel config file",
+	}
+
+	var to string
+	convert := &cobra.Command{
+		Use:   "convert SOURCE [DEST]",
+		Short: "Convert a config file between the HCL, JSON and YAML formats",
+		Long: `
+Convert a config file between the HCL, JSON and YAML formats. The format of each file is selected by its extension.
+
+The JSON format is the JSON syntax of HCL, and the YAML format mirrors it. Expressions that are not literals,
+like function calls, are written as "${...}" interpolations. Comments are not preserved, and included files are not converted.
+
+If DEST is omitted the converted config is printed to standard output.
+`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params := action.ConvertParams{
+				Source: args[0],
+				Stdout: cmd.OutOrStdout(),
+			}
+			if len(args) > 1 {
+				params.Dest = args[1]
+			}
+			if to != "" {
+				f, err := config.ParseFormat(to)
+				if err != nil {
+					return err
+				}
+				params.Format = f
+			}
+
+			return action.ConvertConfig(cmd.Context(), params)
+		},
+	}
+	convert.Flags().StringVar(&to, "to", "", "Format to convert to. One of hcl, json, yaml. Defaults to the extension of DEST, or to json for HCL sources and hcl otherwise")
+	cmd.AddCommand(convert)
+
+	return cmd
+}
+
+// resolveConfigPath returns the absolute path to the config, either a file or a directory whose *.hcl files are merged.
+// If the default config file does not exist, its JSON and YAML alternatives are tried
+func resolveConfigPath(cpath string) (string, error) {
+	cpath, err := filepath.Abs(cpath)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = os.Stat(cpath)
+	if os.IsNotExist(err) && filepath.Base(cpath) == DefaultFileName {
+		for _, name := range alternateFileNames {
+			alt := filepath.Join(filepath.Dir(cpath), name)
+			if _, aerr := os.Stat(alt); aerr == nil {
+				return alt, nil
+			}
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid config file: %w", err)
+	}
+
+	return cpath, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/karavel-io/cli/internal/prompt"
+	"github.com/karavel-io/cli/pkg/action"
+
+	"github.com/spf13/cobra"
+)
+
+func NewAddCommand() *cobra.Command {
+	var cpath string
+	var name string
+	var namespace string
+	var ver string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "add COMPONENT",
+		Short: "Add a component to a Karavel project",
+		Long: `
+Add a component block to the Karavel config file, pinned to the latest available version
+or to the one selected with --version. Params the component requires are added as commented
+out defaults to review. Missing dependencies are added too, after confirmation.
+
+Comments and formatting in the config file are preserved.
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cpath, err := resolveConfigPath(cpath)
+			if err != nil {
+				return err
+			}
+
+			return action.AddComponent(cmd.Context(), action.AddParams{
+				ConfigPath: cpath,
+				Component:  args[0],
+				Name:       name,
+				Namespace:  namespace,
+				Version:    ver,
+				Yes:        yes,
+				Prompter:   prompt.New(cmd.InOrStdin(), cmd.ErrOrStderr()),
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&cpath, "file", "f", DefaultFileName, "Specify an alternate config file, or a directory whose *.hcl files are merged")
+	cmd.Flags().StringVar(&name, "name", "", "Name of the component instance, if different from the component itself")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace to install the component in. Defaults to the one defined by the component")
+	cmd.Flags().StringVarP(&ver, "version", "v", "", "Component version to pin. Prefix it with 'unstable:' to use the unstable repository")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Add missing dependencies without asking for confirmation")
+
+	return cmd
+}
+
+func NewRemoveCommand() *cobra.Command {
+	var cpath string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "remove NAME",
+		Short: "Remove a component from a Karavel project",
+		Long: `
+Remove a component block from the Karavel config file, warning about other components that depend on it.
+
+Comments and formatting in the config file are preserved.
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cpath, err := resolveConfigPath(cpath)
+			if err != nil {
+				return err
+			}
+
+			return action.RemoveComponent(cmd.Context(), action.RemoveParams{
+				ConfigPath: cpath,
+				Name:       args[0],
+				Yes:        yes,
+				Prompter:   prompt.New(cmd.InOrStdin(), cmd.ErrOrStderr()),
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&cpath, "file", "f", DefaultFileName, "Specify an alternate config file, or a directory whose *.hcl files are merged")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Remove the component even if others depend on it, without asking for confirmation")
+
+	return cmd
+}
@@ -0,0 +1,86 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/karavel-io/cli/pkg/action"
+
+	"github.com/spf13/cobra"
+)
+
+func NewImagesCommand() *cobra.Command {
+	var cpath string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "images",
+		Short: "List the container images used by the components of a Karavel project",
+		Long: fmt.Sprintf(`
+List the container images used by the components of a Karavel project with the given config (defaults to '%s' in the current directory).
+
+The components are rendered in memory, nothing is written to disk. If registry_mirrors are configured, the mirrored
+reference of each image is listed as well, so that the mirrors can be seeded before rendering.
+`, DefaultFileName),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cpath, err := resolveConfigPath(cpath)
+			if err != nil {
+				return err
+			}
+
+			images, err := action.ListImages(cmd.Context(), action.ImagesParams{ConfigPath: cpath})
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			switch output {
+			case "", "text":
+				return printImages(out, images)
+			case "json":
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(images)
+			default:
+				return fmt.Errorf("unsupported output format '%s', must be one of text, json", output)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&cpath, "file", "f", DefaultFileName, "Specify an alternate config file, or a directory whose *.hcl files are merged")
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "Output format. One of text, json")
+
+	return cmd
+}
+
+func printImages(out io.Writer, components []action.ComponentImages) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "COMPONENT\tIMAGE\tMIRROR")
+	for _, c := range components {
+		for _, img := range c.Images {
+			mirror := img.Mirror
+			if mirror == "" {
+				mirror = "-"
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", c.Component, img.Name, mirror)
+		}
+	}
+	return w.Flush()
+}
@@ -19,6 +19,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/karavel-io/cli/internal/prompt"
 	"github.com/karavel-io/cli/pkg/action"
 
 	"github.com/spf13/cobra"
@@ -28,13 +29,20 @@ func NewInitCommand() *cobra.Command {
 	var ver string
 	var filename string
 	var force bool
-	var repo string
+	var source string
+	var listVersions bool
+	var interactive bool
+	var tpl string
 
 	cmd := &cobra.Command{
 		Use:   "init [WORKDIR]",
 		Short: "Initialize a new Karavel project",
 		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if listVersions {
+				return printVersions(cmd, source)
+			}
+
 			var cwd string
 			if len(args) > 0 {
 				cwd = args[0]
@@ -59,7 +67,10 @@ func NewInitCommand() *cobra.Command {
 				Filename:       filename,
 				KaravelVersion: ver,
 				Force:          force,
-				GitHubRepo:     repo,
+				ReleaseSource:  source,
+				Interactive:    interactive,
+				Template:       tpl,
+				Prompter:       prompt.New(cmd.InOrStdin(), cmd.ErrOrStderr()),
 			})
 		},
 	}
@@ -67,7 +78,9 @@ func NewInitCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&ver, "version", "v", "latest", "Karavel Container Platform version to initialize")
 	cmd.Flags().StringVarP(&filename, "output-file", "o", DefaultFileName, "Karavel config file name to create")
 	cmd.Flags().BoolVar(&force, "force", false, "Overwrite the config file even if it already exists")
-	cmd.Flags().StringVar(&repo, "github-repo", "", "Override the official GitHub repository containing the tagged Platform releases")
+	addReleaseSourceFlags(cmd, &source, &listVersions)
+	cmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Interactively select the version (unless --version is set), a components preset, the domain and namespaces")
+	cmd.Flags().StringVar(&tpl, "template", "", "Initialize the project from a starter kit, either a local directory or a git repository URL")
 
 	return cmd
 }
@@ -0,0 +1,64 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/karavel-io/cli/internal/inventory"
+	"github.com/karavel-io/cli/pkg/action"
+
+	"github.com/spf13/cobra"
+)
+
+func NewInventoryCommand() *cobra.Command {
+	var cpath string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "inventory",
+		Short: "Export an inventory of the components, images and CRDs of a Karavel project",
+		Long: fmt.Sprintf(`
+Export an inventory of a Karavel project with the given config (defaults to '%s' in the current directory)
+as a CycloneDX or SPDX JSON document.
+
+The components are rendered in memory, nothing is written to disk. The inventory lists each component
+with its chart version and repository, along with the container images and CRDs it ships.
+`, DefaultFileName),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != inventory.FormatCycloneDX && output != inventory.FormatSPDX {
+				return fmt.Errorf("unsupported output format '%s', must be one of %s, %s", output, inventory.FormatCycloneDX, inventory.FormatSPDX)
+			}
+
+			cpath, err := resolveConfigPath(cpath)
+			if err != nil {
+				return err
+			}
+
+			inv, err := action.Inventory(cmd.Context(), action.InventoryParams{ConfigPath: cpath})
+			if err != nil {
+				return err
+			}
+
+			return inv.Write(cmd.OutOrStdout(), output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&cpath, "file", "f", DefaultFileName, "Specify an alternate config file, or a directory whose *.hcl files are merged")
+	cmd.Flags().StringVarP(&output, "output", "o", inventory.FormatCycloneDX, fmt.Sprintf("Output format. One of %s, %s", inventory.FormatCycloneDX, inventory.FormatSPDX))
+
+	return cmd
+}
@@ -16,6 +16,7 @@ package main
 
 import (
 	"context"
+	"os"
 	"time"
 
 	"github.com/karavel-io/cli/internal/version"
@@ -28,6 +29,7 @@ func main() {
 	var debug bool
 	var quiet bool
 	var colors bool
+	var updateNotice bool
 
 	log := logger.New(logger.LvlInfo)
 
@@ -45,15 +47,38 @@ func main() {
 				log.SetLevel(logger.LvlError)
 			}
 		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			switch cmd.Name() {
+			case "self-update", "version", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+				return
+			}
+			if cmd.HasParent() && cmd.Parent().Name() == "completion" {
+				return
+			}
+
+			if (updateNotice || os.Getenv("KARAVEL_UPDATE_NOTICE") != "") && !quiet {
+				printUpdateNotice(cmd.Context(), log)
+			}
+		},
 	}
 
 	app.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "Output debug logs")
 	app.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress all logs except errors")
 	app.PersistentFlags().BoolVar(&colors, "colors", true, "Enable colored logs")
+	app.PersistentFlags().BoolVar(&updateNotice, "update-notice", false, "Notify when a newer CLI release is available, checking once a day. Can also be enabled by setting KARAVEL_UPDATE_NOTICE")
 
+	app.AddCommand(NewAddCommand())
+	app.AddCommand(NewComponentsCommand())
+	app.AddCommand(NewConfigCommand())
+	app.AddCommand(NewImagesCommand())
 	app.AddCommand(NewInitCommand())
+	app.AddCommand(NewInventoryCommand())
+	app.AddCommand(NewRemoveCommand())
 	app.AddCommand(NewRenderCommand())
+	app.AddCommand(NewSelfUpdateCommand())
+	app.AddCommand(NewUpgradeCommand())
 	app.AddCommand(NewVersionCommand())
+	app.AddCommand(NewWatchCommand())
 
 	ctx, cancel := context.WithTimeout(logger.WithLogger(context.Background(), log), 15*time.Minute)
 	defer cancel()
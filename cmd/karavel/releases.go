@@ -0,0 +1,49 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/karavel-io/cli/pkg/action"
+
+	"github.com/spf13/cobra"
+)
+
+const releaseSourceUsage = `Where to look up the platform releases. One of:
+owner/repo or github:<owner/repo|url>, gitlab:<group/project|url>, gitea:<url>, helm:<repo-url>#<chart>.
+API tokens are read from GITHUB_TOKEN, GITLAB_TOKEN and GITEA_TOKEN`
+
+// addReleaseSourceFlags registers the flags selecting the release source, including the deprecated --github-repo
+func addReleaseSourceFlags(cmd *cobra.Command, source *string, listVersions *bool) {
+	cmd.Flags().StringVar(source, "release-source", "", releaseSourceUsage)
+	cmd.Flags().StringVar(source, "github-repo", "", "Override the official GitHub repository containing the tagged Platform releases")
+	_ = cmd.Flags().MarkDeprecated("github-repo", "use --release-source instead")
+	cmd.Flags().BoolVar(listVersions, "list-versions", false, "List the available platform releases and exit")
+}
+
+func printVersions(cmd *cobra.Command, source string) error {
+	versions, err := action.ListVersions(cmd.Context(), source)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range versions {
+		if _, err := fmt.Fprintln(cmd.OutOrStdout(), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -16,19 +16,23 @@ package main
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 
+	"github.com/karavel-io/cli/internal/lockfile"
+	"github.com/karavel-io/cli/internal/utils"
 	"github.com/karavel-io/cli/pkg/action"
 
 	"github.com/spf13/cobra"
 )
 
-const DefaultFileName = "karavel.hcl"
-
 func NewRenderCommand() *cobra.Command {
 	var cpath string
 	var skipGit bool
+	var gitRemote string
+	var commit bool
+	var pinDigests bool
+	var output string
+	var parallelism int
+	var force bool
 
 	cmd := &cobra.Command{
 		Use:   "render",
@@ -39,40 +43,42 @@ Render a Karavel project with the given config (defaults to '%s' in the current
 This command is idempotent and can be run multiple times without issues. 
 It will respect changes made to files outside the 'vendor' directory, only adding or removing Karavel-specific entries.
 It will, however, consider the 'vendor' directory as a fully-managed folder and may add, delete or modify any file inside it without warning.
-`, DefaultFileName),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			cpath, err := filepath.Abs(cpath)
-			if err != nil {
-				return err
-			}
-
-			cstat, err := os.Stat(cpath)
-			if err != nil {
-				return err
-			}
 
-			if cstat.IsDir() && cstat.Name() != DefaultFileName {
-				cpath = filepath.Join(cpath, DefaultFileName)
-			}
+Every render writes the resolved component versions, and the image digests pinned with --pin-digests, to '%s'
+next to the config. It is managed by Karavel and meant to be committed along with the rendered files.
 
-			cstat, err = os.Stat(cpath)
+Components whose inputs and rendered files did not change since the last render are skipped. Their state is recorded
+in '.karavel/state', which is local to the machine and ignored by git. Use --force to render every component.
+`, DefaultFileName, lockfile.Filename),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cpath, err := resolveConfigPath(cpath)
 			if err != nil {
 				return err
 			}
 
-			if cstat.IsDir() {
-				return fmt.Errorf("invalid config file %s, is a directory", cpath)
-			}
-
 			return action.Render(cmd.Context(), action.RenderParams{
-				ConfigPath: cpath,
-				SkipGit:    skipGit,
+				ConfigPath:  cpath,
+				SkipGit:     skipGit,
+				GitRemote:   gitRemote,
+				Commit:      commit,
+				PinDigests:  pinDigests,
+				Output:      output,
+				Stdout:      cmd.OutOrStdout(),
+				Force:       force,
+				Parallelism: parallelism,
 			})
 		},
 	}
 
-	cmd.Flags().StringVarP(&cpath, "file", "f", DefaultFileName, "Specify an alternate config file")
+	cmd.Flags().StringVarP(&cpath, "file", "f", DefaultFileName, "Specify an alternate config file, or a directory whose *.hcl files are merged")
 	cmd.Flags().BoolVar(&skipGit, "skip-git", false, "Skip the git integration to discover remote repositories for Argo. WARNING: this will render the Argo component inoperable")
+	cmd.Flags().StringVar(&gitRemote, "git-remote", "", "Name of the git remote used to configure Argo applications. Defaults to the 'git.remote' param of the argocd component, or to the only remote or 'origin'")
+	cmd.Flags().BoolVar(&commit, "commit", false, "Commit the rendered files to git. Refuses to run if files not managed by Karavel have uncommitted changes")
+
+	cmd.Flags().StringVarP(&output, "output", "o", action.OutputDir, fmt.Sprintf("Where to render the project. '%s' writes the project files, '%s' or '%s<path>' write all the documents as a single YAML stream in dependency order, without touching the project files", action.OutputDir, action.OutputStdout, action.OutputFilePrefix))
+	cmd.Flags().IntVar(&parallelism, "parallelism", utils.DefaultParallelism(), "Maximum number of components loaded and rendered at the same time")
+	cmd.Flags().BoolVar(&pinDigests, "pin-digests", false, "Pin the container images to their digest, failing if one cannot be resolved. Digests are cached in the lockfile, remove them and render with --force to resolve them again")
+	cmd.Flags().BoolVar(&force, "force", false, "Render every component, even the ones that did not change since the last render")
 
 	return cmd
 }
@@ -0,0 +1,81 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/karavel-io/cli/internal/selfupdate"
+	"github.com/karavel-io/cli/internal/version"
+	"github.com/karavel-io/cli/pkg/action"
+	"github.com/karavel-io/cli/pkg/logger"
+
+	"github.com/spf13/cobra"
+)
+
+const noticeTimeout = 2 * time.Second
+
+func NewSelfUpdateCommand() *cobra.Command {
+	var params action.SelfUpdateParams
+
+	cmd := &cobra.Command{
+		Use:   "self-update",
+		Short: "Update the Karavel CLI to the latest release",
+		Long: `
+Update the Karavel CLI to the latest release, or to the one selected with --version.
+
+The release archive for the current OS and architecture is downloaded from GitHub and verified
+against the release checksums (and their GPG signature, if the release is signed) before
+atomically replacing the running executable.
+`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return action.SelfUpdate(cmd.Context(), params)
+		},
+	}
+
+	cmd.Flags().StringVar(&params.Version, "version", "latest", "Karavel CLI version to install")
+	cmd.Flags().BoolVar(&params.CheckOnly, "check", false, "Only check whether a newer release is available")
+	cmd.Flags().BoolVar(&params.Force, "force", false, "Install the release even if it is not newer than the running CLI")
+	cmd.Flags().StringVar(&params.GitHubRepo, "github-repo", "", "Override the official GitHub repository publishing the CLI releases")
+
+	return cmd
+}
+
+// printUpdateNotice logs a notice if a newer CLI release is available, checking at most once a day
+func printUpdateNotice(ctx context.Context, log logger.Logger) {
+	cachePath, err := selfupdate.NoticeCachePath()
+	if err != nil {
+		log.Debugf("Skipping update check: %s", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, noticeTimeout)
+	defer cancel()
+
+	src := selfupdate.Source{Token: os.Getenv("GITHUB_TOKEN")}
+	latest, err := selfupdate.CheckNotice(ctx, src, version.Get().Version, cachePath, time.Now())
+	if err != nil {
+		log.Debugf("Failed to check for CLI updates: %s", err)
+		return
+	}
+
+	if latest != "" {
+		log.Info()
+		log.Infof("Karavel CLI %s is available, run 'karavel self-update' to install it", latest)
+	}
+}
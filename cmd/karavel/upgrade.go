@@ -0,0 +1,77 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+
+	"github.com/karavel-io/cli/pkg/action"
+
+	"github.com/spf13/cobra"
+)
+
+func NewUpgradeCommand() *cobra.Command {
+	var cpath string
+	var ver string
+	var source string
+	var listVersions bool
+	var render bool
+	var skipGit bool
+	var gitRemote string
+
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade a Karavel project to a new platform release",
+		Long: `
+Upgrade a Karavel project to a new Karavel Container Platform release (defaults to the latest one).
+
+The 'version' attribute in the config file is updated in place, preserving comments and formatting.
+Components whose chart version changes between the two releases are listed, together with warnings
+about components that are no longer available or have been moved to the unstable repository.
+`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if listVersions {
+				return printVersions(cmd, source)
+			}
+
+			cpath, err := resolveConfigPath(cpath)
+			if err != nil {
+				return err
+			}
+
+			return action.Upgrade(cmd.Context(), action.UpgradeParams{
+				ConfigPath:     cpath,
+				KaravelVersion: strings.TrimPrefix(ver, "v"),
+				ReleaseSource:  source,
+				Render:         render,
+				RenderParams: action.RenderParams{
+					SkipGit:   skipGit,
+					GitRemote: gitRemote,
+					Stdout:    cmd.OutOrStdout(),
+				},
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&cpath, "file", "f", DefaultFileName, "Specify an alternate config file, or a directory whose *.hcl files are merged")
+	cmd.Flags().StringVar(&ver, "to", "latest", "Karavel Container Platform version to upgrade to")
+	addReleaseSourceFlags(cmd, &source, &listVersions)
+	cmd.Flags().BoolVar(&render, "render", false, "Render the project after upgrading it")
+	cmd.Flags().BoolVar(&skipGit, "skip-git", false, "Skip the git integration when rendering with --render. WARNING: this will render the Argo component inoperable")
+	cmd.Flags().StringVar(&gitRemote, "git-remote", "", "Name of the git remote used to configure Argo applications when rendering with --render")
+
+	return cmd
+}
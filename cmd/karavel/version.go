@@ -15,20 +15,47 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/karavel-io/cli/internal/version"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 func NewVersionCommand() *cobra.Command {
-	return &cobra.Command{
+	var output string
+
+	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "Prints the CLI version and exits",
+		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			_, err := fmt.Printf("%+v", version.Get())
-			return err
+			v := version.Get()
+			out := cmd.OutOrStdout()
+			switch output {
+			case "", "text":
+				_, err := fmt.Fprintf(out, "%+v", v)
+				return err
+			case "json":
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(v)
+			case "yaml":
+				enc := yaml.NewEncoder(out)
+				enc.SetIndent(2)
+				if err := enc.Encode(v); err != nil {
+					return err
+				}
+				return enc.Close()
+			default:
+				return fmt.Errorf("unsupported output format '%s', must be one of text, json, yaml", output)
+			}
 		},
 	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "Output format. One of text, json, yaml")
+
+	return cmd
 }
@@ -0,0 +1,83 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/karavel-io/cli/internal/utils"
+	"github.com/karavel-io/cli/pkg/action"
+	"github.com/karavel-io/cli/pkg/logger"
+
+	"github.com/spf13/cobra"
+)
+
+func NewWatchCommand() *cobra.Command {
+	var cpath string
+	var skipGit bool
+	var gitRemote string
+	var pinDigests bool
+	var parallelism int
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Render a Karavel project every time its config changes",
+		Long: fmt.Sprintf(`
+Render a Karavel project with the given config (defaults to '%s' in the current directory),
+then render it again every time the config or one of the local files it refers to changes.
+
+Only the components affected by a change are rendered again, and a summary of the changed files is printed after each render.
+Render failures are reported without stopping the watch. Press Ctrl+C to stop.
+`, DefaultFileName),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cpath, err := resolveConfigPath(cpath)
+			if err != nil {
+				return err
+			}
+
+			// watching runs until interrupted, without the timeout of the other commands
+			ctx, stop := signal.NotifyContext(logger.WithLogger(context.Background(), logger.FromContext(cmd.Context())), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return action.Watch(ctx, action.WatchParams{
+				Render: action.RenderParams{
+					ConfigPath:  cpath,
+					SkipGit:     skipGit,
+					GitRemote:   gitRemote,
+					PinDigests:  pinDigests,
+					Stdout:      cmd.OutOrStdout(),
+					Parallelism: parallelism,
+				},
+				Interval: interval,
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&cpath, "file", "f", DefaultFileName, "Specify an alternate config file, or a directory whose *.hcl files are merged")
+	cmd.Flags().BoolVar(&skipGit, "skip-git", false, "Skip the git integration to discover remote repositories for Argo. WARNING: this will render the Argo component inoperable")
+	cmd.Flags().StringVar(&gitRemote, "git-remote", "", "Name of the git remote used to configure Argo applications. Defaults to the 'git.remote' param of the argocd component, or to the only remote or 'origin'")
+	cmd.Flags().BoolVar(&pinDigests, "pin-digests", false, "Pin the container images to their digest, failing if one cannot be resolved")
+	cmd.Flags().IntVar(&parallelism, "parallelism", utils.DefaultParallelism(), "Maximum number of components loaded and rendered at the same time")
+	cmd.Flags().DurationVar(&interval, "interval", time.Second, "How often the config and the files it refers to are checked for changes")
+
+	return cmd
+}
@@ -39,8 +39,9 @@ type ApplicationSpec struct {
 }
 
 type Source struct {
-	RepoUrl string `yaml:"repoURL"`
-	Path    string `yaml:"path"`
+	RepoUrl        string `yaml:"repoURL"`
+	Path           string `yaml:"path"`
+	TargetRevision string `yaml:"targetRevision,omitempty"`
 }
 
 type Destination struct {
@@ -71,7 +72,7 @@ type Backoff struct {
 	MaxDuration time.Duration `yaml:"maxDuration"`
 }
 
-func NewApplication(name string, namespace string, argoNs string, repoUrl string, path string) Application {
+func NewApplication(name string, namespace string, argoNs string, repoUrl string, revision string, path string) Application {
 	return Application{
 		TypeMeta: TypeMeta{
 			APIVersion: "argoproj.io/v1alpha1",
@@ -86,8 +87,9 @@ func NewApplication(name string, namespace string, argoNs string, repoUrl string
 		},
 		Spec: ApplicationSpec{
 			Source: Source{
-				RepoUrl: repoUrl,
-				Path:    path,
+				RepoUrl:        repoUrl,
+				Path:           path,
+				TargetRevision: revision,
 			},
 			Destination: Destination{
 				Server:    "https://kubernetes.default.svc",
@@ -0,0 +1,162 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitutils
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+
+	"github.com/karavel-io/cli/pkg/logger"
+)
+
+// AssertClean fails if the repository containing cwd has uncommitted changes outside the allowed paths.
+// Allowed paths are absolute and may point to either files or directories. Changes to files whose absolute path
+// starts with one of the ignored prefixes, like temporary directories, are not reported either.
+func AssertClean(log logger.Logger, cwd string, allowed []string, ignored []string) error {
+	r, root, err := openRepo(log, cwd)
+	if err != nil {
+		return err
+	}
+
+	prefixes, err := relPaths(root, allowed)
+	if err != nil {
+		return err
+	}
+
+	ignoredPrefixes, err := relPaths(root, ignored)
+	if err != nil {
+		return err
+	}
+
+	st, err := status(r)
+	if err != nil {
+		return err
+	}
+
+	var dirty []string
+	for file := range st {
+		if !isUnderAny(file, prefixes) && !hasAnyPrefix(file, ignoredPrefixes) {
+			dirty = append(dirty, file)
+		}
+	}
+
+	if len(dirty) > 0 {
+		sort.Strings(dirty)
+		return fmt.Errorf("git repo %s has uncommitted changes to files not managed by Karavel: %s", root, strings.Join(dirty, ", "))
+	}
+
+	return nil
+}
+
+// CommitPaths stages every change under the given absolute paths and commits them with the given message.
+// It returns the hash of the new commit, or an empty string if there was nothing to commit.
+func CommitPaths(log logger.Logger, cwd string, paths []string, message string) (string, error) {
+	r, root, err := openRepo(log, cwd)
+	if err != nil {
+		return "", err
+	}
+
+	prefixes, err := relPaths(root, paths)
+	if err != nil {
+		return "", err
+	}
+
+	wt, err := r.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	st, err := wt.Status()
+	if err != nil {
+		return "", err
+	}
+
+	staged := 0
+	for file, fs := range st {
+		if !isUnderAny(file, prefixes) {
+			continue
+		}
+
+		if fs.Worktree == git.Deleted {
+			log.Debugf("git rm %s", file)
+			_, err = wt.Remove(file)
+		} else {
+			log.Debugf("git add %s", file)
+			_, err = wt.Add(file)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to stage %s: %w", file, err)
+		}
+		staged++
+	}
+
+	if staged == 0 {
+		return "", nil
+	}
+
+	hash, err := wt.Commit(message, &git.CommitOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return hash.String(), nil
+}
+
+func status(r *git.Repository) (git.Status, error) {
+	wt, err := r.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	return wt.Status()
+}
+
+func relPaths(root string, paths []string) ([]string, error) {
+	rels := make([]string, len(paths))
+	for i, p := range paths {
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasPrefix(rel, "..") {
+			return nil, fmt.Errorf("path %s is outside of git repo %s", p, root)
+		}
+		rels[i] = filepath.ToSlash(rel)
+	}
+	return rels, nil
+}
+
+func isUnderAny(file string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if p == "." || file == p || strings.HasPrefix(file, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPrefix(file string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(file, p) {
+			return true
+		}
+	}
+	return false
+}
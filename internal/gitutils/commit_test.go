@@ -0,0 +1,85 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestCommitPaths(t *testing.T) {
+	dir := initRepo(t, nil)
+	r, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+
+	cfg, err := r.Config()
+	require.NoError(t, err)
+	cfg.User.Name = "Karavel"
+	cfg.User.Email = "karavel@example.com"
+	require.NoError(t, r.SetConfig(cfg))
+
+	wt, err := r.Worktree()
+	require.NoError(t, err)
+
+	writeFile(t, filepath.Join(dir, "README.md"), "hello")
+	writeFile(t, filepath.Join(dir, "vendor", "old", "deployment.yml"), "old")
+	_, err = wt.Add(".")
+	require.NoError(t, err)
+	_, err = wt.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	vendor := filepath.Join(dir, "vendor")
+	managed := []string{vendor, filepath.Join(dir, "karavel.hcl")}
+
+	require.NoError(t, os.RemoveAll(filepath.Join(vendor, "old")))
+	writeFile(t, filepath.Join(vendor, "new", "deployment.yml"), "new")
+	writeFile(t, filepath.Join(dir, "karavel.hcl"), `version = "2022.1"`)
+	assert.NoError(t, AssertClean(log, dir, managed, nil))
+
+	writeFile(t, filepath.Join(dir, ".render-1234", "vendor", "deployment.yml"), "tmp")
+	assert.Error(t, AssertClean(log, dir, managed, nil))
+	assert.NoError(t, AssertClean(log, dir, managed, []string{filepath.Join(dir, ".render-")}))
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, ".render-1234")))
+
+	writeFile(t, filepath.Join(dir, "README.md"), "changed")
+	assert.Error(t, AssertClean(log, dir, managed, nil))
+
+	hash, err := CommitPaths(log, dir, managed, "render")
+	require.NoError(t, err)
+	assert.NotEmpty(t, hash)
+
+	st, err := wt.Status()
+	require.NoError(t, err)
+	assert.Len(t, st, 1)
+	assert.Equal(t, git.Modified, st.File("README.md").Worktree)
+
+	hash, err = CommitPaths(log, dir, managed, "render")
+	require.NoError(t, err)
+	assert.Empty(t, hash)
+}
@@ -17,41 +17,38 @@ package gitutils
 import (
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
 
-	"github.com/go-git/go-billy/v5/osfs"
 	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing/cache"
-	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/storer"
 
 	"github.com/karavel-io/cli/pkg/logger"
 )
 
-func GetOriginRemote(log logger.Logger, cwd string, urlOverride string) (string, string, error) {
-	path, err := findGitRepoPath(log, cwd)
+// GetRemote returns the root path of the git repository containing cwd, together with the URL of one of its remotes.
+// If remoteName is empty, the only configured remote or the one called origin is picked.
+func GetRemote(log logger.Logger, cwd string, remoteName string, urlOverride string) (string, string, error) {
+	r, path, err := openRepo(log, cwd)
 	if err != nil {
 		return "", "", err
 	}
 
-	fs := osfs.New(path)
-	if _, err := fs.Stat(git.GitDirName); err == nil {
-		fs, err = fs.Chroot(git.GitDirName)
+	if urlOverride != "" {
+		return path, urlOverride, nil
+	}
+
+	if remoteName != "" {
+		rem, err := r.Remote(remoteName)
 		if err != nil {
-			return "", "", err
+			return "", "", fmt.Errorf("failed to find git remote %s for git repo %s: %w", remoteName, path, err)
 		}
-	}
 
-	s := filesystem.NewStorageWithOptions(fs, cache.NewObjectLRUDefault(), filesystem.Options{KeepDescriptors: true})
-	r, err := git.Open(s, fs)
-	if err != nil {
-		return "", "", err
-	}
+		urls := rem.Config().URLs
+		if len(urls) > 0 {
+			return path, urls[0], nil
+		}
 
-	// remove the .git step
-	path = filepath.Dir(path)
-	if urlOverride != "" {
-		return path, urlOverride, nil
+		return "", "", fmt.Errorf("git remote %s has no URL defined", remoteName)
 	}
 
 	rems, err := r.Remotes()
@@ -84,36 +81,84 @@ func GetOriginRemote(log logger.Logger, cwd string, urlOverride string) (string,
 			return "", "", fmt.Errorf("git remote %s has no URL defined", c.Name)
 		}
 	}
-	return "", "", fmt.Errorf("could not find a suitable remote for git repo %s", path)
+	return "", "", fmt.Errorf("could not find a suitable remote for git repo %s, select one explicitly", path)
 }
 
-func findGitRepoPath(log logger.Logger, path string) (string, error) {
-	log.Debugf("looking for repo for %s", path)
+// GetCurrentRevision returns the name of the branch currently checked out in the repository containing cwd.
+// If HEAD is detached, it falls back to a tag pointing at the current commit.
+// An empty revision, meaning HEAD, is returned if the repository has no commits yet or no tag matches a detached HEAD:
+// pinning a commit hash would freeze the applications on it, since existing application files are never rewritten.
+func GetCurrentRevision(log logger.Logger, cwd string) (string, error) {
+	r, path, err := openRepo(log, cwd)
+	if err != nil {
+		return "", err
+	}
+
+	head, err := r.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		log.Debugf("git repo %s has no commits yet, the revision will not be set", path)
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD for git repo %s: %w", path, err)
+	}
+
+	if head.Name().IsBranch() {
+		return head.Name().Short(), nil
+	}
 
-	gitPath := filepath.Join(path, git.GitDirName)
-	i, err := os.Stat(gitPath)
-	if err != nil && !os.IsNotExist(err) {
+	log.Debugf("HEAD is detached at %s, looking for a matching tag", head.Hash())
+	tags, err := r.Tags()
+	if err != nil {
 		return "", err
 	}
 
-	if os.IsNotExist(err) {
-		up := filepath.Dir(path)
-		log.Debugf("no git dir found, going up one notch from %s to %s", path, up)
-		if up == "" || up == "." || up == "/" {
-			return "", errors.New("reached top of fs without finding a git repository")
+	var tagName string
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		target := ref.Hash()
+		// annotated tags point to a tag object instead of the commit itself
+		if tag, err := r.TagObject(target); err == nil {
+			target = tag.Target
 		}
-		return findGitRepoPath(log, up)
-	}
 
-	if !i.IsDir() || i.Name() != git.GitDirName {
-		var d string
-		if i.IsDir() {
-			d = " not"
+		if target == head.Hash() {
+			tagName = ref.Name().Short()
+			return storer.ErrStop
 		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
 
-		return "", fmt.Errorf("failed to find git repo, found %s which is%s a directory", i.Name(), d)
+	if tagName == "" {
+		log.Warnf("HEAD is detached at %s and no tag points to it, applications will track HEAD. Set the git.revision param of the argocd component to pick a branch", head.Hash())
+	}
+
+	return tagName, nil
+}
+
+// openRepo opens the git repository containing cwd, looking in parent directories if necessary.
+// Linked worktrees and submodules, where .git is a file pointing to the actual git directory, are supported.
+func openRepo(log logger.Logger, cwd string) (*git.Repository, string, error) {
+	log.Debugf("looking for repo for %s", cwd)
+	r, err := git.PlainOpenWithOptions(cwd, &git.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
+	})
+	if err == git.ErrRepositoryNotExists {
+		return nil, "", fmt.Errorf("failed to find a git repository for %s", cwd)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	wt, err := r.Worktree()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open git worktree for %s: %w", cwd, err)
 	}
 
-	log.Debugf("found git repo at %s", gitPath)
-	return gitPath, nil
+	path := wt.Filesystem.Root()
+	log.Debugf("found git repo at %s", path)
+	return r, path, nil
 }
@@ -0,0 +1,156 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/karavel-io/cli/pkg/logger"
+)
+
+var log = logger.New(logger.LvlError)
+
+func initRepo(t *testing.T, remotes map[string]string) string {
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	for name, url := range remotes {
+		_, err := r.CreateRemote(&config.RemoteConfig{Name: name, URLs: []string{url}})
+		require.NoError(t, err)
+	}
+
+	return dir
+}
+
+func TestGetRemote(t *testing.T) {
+	dir := initRepo(t, map[string]string{
+		"origin":   "git@github.com:karavel-io/platform.git",
+		"upstream": "https://gitlab.example.com/infra/platform.git",
+	})
+
+	sub := filepath.Join(dir, "clusters", "prod")
+	require.NoError(t, os.MkdirAll(sub, 0o755))
+
+	path, url, err := GetRemote(log, sub, "", "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, dir, path)
+		assert.Equal(t, "git@github.com:karavel-io/platform.git", url)
+	}
+
+	_, url, err = GetRemote(log, sub, "upstream", "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "https://gitlab.example.com/infra/platform.git", url)
+	}
+
+	_, _, err = GetRemote(log, sub, "missing", "")
+	assert.Error(t, err)
+}
+
+func TestGetRemoteNoOrigin(t *testing.T) {
+	dir := initRepo(t, map[string]string{
+		"github": "git@github.com:karavel-io/platform.git",
+		"gitlab": "https://gitlab.example.com/infra/platform.git",
+	})
+
+	_, _, err := GetRemote(log, dir, "", "")
+	assert.Error(t, err)
+
+	_, url, err := GetRemote(log, dir, "gitlab", "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "https://gitlab.example.com/infra/platform.git", url)
+	}
+}
+
+func TestGetRemoteDotGitFile(t *testing.T) {
+	repo := initRepo(t, map[string]string{
+		"origin": "git@github.com:karavel-io/platform.git",
+	})
+
+	// a .git file pointing to the actual git directory, like the ones created for submodules
+	dir := t.TempDir()
+	gitdir := "gitdir: " + filepath.Join(repo, git.GitDirName) + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, git.GitDirName), []byte(gitdir), 0o644))
+
+	path, url, err := GetRemote(log, dir, "", "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, dir, path)
+		assert.Equal(t, "git@github.com:karavel-io/platform.git", url)
+	}
+}
+
+func TestGetCurrentRevision(t *testing.T) {
+	dir := initRepo(t, nil)
+
+	// a repository without commits has no revision yet
+	rev, err := GetCurrentRevision(log, dir)
+	require.NoError(t, err)
+	assert.Empty(t, rev)
+
+	r, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	wt, err := r.Worktree()
+	require.NoError(t, err)
+
+	sig := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	commit := func(msg string) plumbing.Hash {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte(msg), 0o644))
+		_, err := wt.Add("README.md")
+		require.NoError(t, err)
+		h, err := wt.Commit(msg, &git.CommitOptions{Author: sig})
+		require.NoError(t, err)
+		return h
+	}
+	first := commit("first")
+	second := commit("second")
+
+	require.NoError(t, wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("release"), Create: true}))
+	rev, err = GetCurrentRevision(log, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "release", rev)
+
+	// a detached HEAD resolves to a tag pointing at it, either lightweight or annotated
+	_, err = r.CreateTag("v1.0.0", first, nil)
+	require.NoError(t, err)
+	_, err = r.CreateTag("v2.0.0", second, &git.CreateTagOptions{Tagger: sig, Message: "v2.0.0"})
+	require.NoError(t, err)
+
+	require.NoError(t, wt.Checkout(&git.CheckoutOptions{Hash: first}))
+	rev, err = GetCurrentRevision(log, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", rev)
+
+	require.NoError(t, wt.Checkout(&git.CheckoutOptions{Hash: second}))
+	rev, err = GetCurrentRevision(log, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "v2.0.0", rev)
+
+	// and to HEAD if no tag matches, as a commit hash would pin the applications forever
+	third := commit("third")
+	require.NoError(t, wt.Checkout(&git.CheckoutOptions{Hash: third}))
+	rev, err = GetCurrentRevision(log, dir)
+	require.NoError(t, err)
+	assert.Empty(t, rev)
+}
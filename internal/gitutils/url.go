@@ -0,0 +1,115 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitutils
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+const (
+	SchemeHTTPS = "https"
+	SchemeSSH   = "ssh"
+)
+
+type remoteUrl struct {
+	// scheme is the protocol of the URL, scp-like URLs use SSH
+	scheme string
+	user   string
+	host   string
+	// port is only set if the URL declares it explicitly
+	port string
+	path string
+}
+
+// ConvertRemoteUrl rewrites a git remote URL to use the given scheme, either "https" or "ssh".
+// SSH URLs are returned in the scp-like form (git@host:org/repo) that most git hosting services advertise,
+// unless they use a custom port (ssh://git@host:port/org/repo). The port is only kept if the scheme does not change,
+// as it belongs to the original protocol. An empty scheme returns the URL unchanged.
+func ConvertRemoteUrl(rawUrl string, scheme string) (string, error) {
+	if scheme == "" {
+		return rawUrl, nil
+	}
+
+	u, err := parseRemoteUrl(rawUrl)
+	if err != nil {
+		return "", err
+	}
+
+	scheme = strings.ToLower(scheme)
+	host := u.host
+	if u.port != "" && u.scheme == scheme {
+		host = net.JoinHostPort(u.host, u.port)
+	}
+
+	switch scheme {
+	case SchemeHTTPS:
+		return fmt.Sprintf("https://%s/%s", host, u.path), nil
+	case SchemeSSH:
+		user := u.user
+		if user == "" {
+			user = "git"
+		}
+		if host != u.host {
+			return fmt.Sprintf("ssh://%s@%s/%s", user, host, u.path), nil
+		}
+		return fmt.Sprintf("%s@%s:%s", user, host, u.path), nil
+	default:
+		return "", fmt.Errorf("unsupported git URL scheme '%s', must be one of '%s' or '%s'", scheme, SchemeHTTPS, SchemeSSH)
+	}
+}
+
+func parseRemoteUrl(rawUrl string) (remoteUrl, error) {
+	if !strings.Contains(rawUrl, "://") {
+		// scp-like syntax, e.g. git@github.com:karavel-io/platform.git
+		userHost, path, ok := strings.Cut(rawUrl, ":")
+		if !ok || path == "" {
+			return remoteUrl{}, fmt.Errorf("invalid git remote URL '%s'", rawUrl)
+		}
+
+		user, host, ok := strings.Cut(userHost, "@")
+		if !ok {
+			host, user = user, ""
+		}
+
+		return remoteUrl{scheme: SchemeSSH, user: user, host: host, path: strings.TrimPrefix(path, "/")}, nil
+	}
+
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return remoteUrl{}, fmt.Errorf("invalid git remote URL '%s': %w", rawUrl, err)
+	}
+
+	var user string
+	scheme := u.Scheme
+	switch scheme {
+	case "ssh", "git+ssh":
+		// only SSH users are meaningful, HTTP(S) credentials must not leak into an SSH URL
+		user = u.User.Username()
+		scheme = SchemeSSH
+	case "http", "https", "git":
+	default:
+		return remoteUrl{}, fmt.Errorf("unsupported git remote URL '%s'", rawUrl)
+	}
+
+	path := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || path == "" {
+		return remoteUrl{}, fmt.Errorf("invalid git remote URL '%s'", rawUrl)
+	}
+
+	return remoteUrl{scheme: scheme, user: user, host: u.Hostname(), port: u.Port(), path: path}, nil
+}
@@ -16,6 +16,8 @@ package helmw
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
@@ -30,6 +32,16 @@ import (
 )
 
 func GetChartManifest(ctx context.Context, chartName string, version string, unstable bool) (*chart.Metadata, error) {
+	ch, err := LoadChart(ctx, chartName, version, unstable)
+	if err != nil {
+		return nil, err
+	}
+
+	return ch.Metadata, nil
+}
+
+// LoadChart downloads and loads a chart from the Karavel repositories registered in the context
+func LoadChart(ctx context.Context, chartName string, version string, unstable bool) (*chart.Chart, error) {
 	repo := HelmRepoName
 	if unstable {
 		repo = UnstableRepoName()
@@ -45,12 +57,49 @@ func GetChartManifest(ctx context.Context, chartName string, version string, uns
 		return nil, err
 	}
 
-	ch, err := loader.Load(path)
+	return loader.Load(path)
+}
+
+// ChartDigest returns the digest of a chart version, as published in the index of the Karavel repositories registered
+// in the context. It is computed from the chart archive if the index does not provide it.
+func ChartDigest(ctx context.Context, chartName string, version string, unstable bool) (string, error) {
+	repo := HelmRepoName
+	if unstable {
+		repo = UnstableRepoName()
+	}
+
+	index, err := RepositoryIndex(ctx, repo)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	return ch.Metadata, nil
+	cv, err := index.Get(chartName, version)
+	if err != nil {
+		return "", err
+	}
+	if cv.Digest != "" {
+		return cv.Digest, nil
+	}
+
+	hshow := action.NewShow(action.ShowAll)
+	hshow.Devel = unstable
+	hshow.Version = version
+	path, err := hshow.LocateChart(fmt.Sprintf("%s/%s", repo, chartName), settingsFromContext(ctx))
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
 type YamlDoc map[string]any
@@ -17,15 +17,22 @@ package helmw
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/karavel-io/cli/pkg/logger"
+	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/helmpath"
 	"helm.sh/helm/v3/pkg/repo"
 )
 
 const (
 	HelmRepoName    = "karavel"
 	HelmDefaultRepo = "https://repository.platform.karavel.io"
+
+	// MinCliVersionAnnotation on a platform release repository index declares the oldest CLI able to render it
+	MinCliVersionAnnotation = "karavel.io/min-cli-version"
 )
 
 var ErrVersionEmpty = fmt.Errorf("version cannot be empty")
@@ -86,6 +93,40 @@ func WithRepository(ctx context.Context, entry *repo.Entry) (context.Context, er
 	return withSettings(withStore(ctx, store), settings), nil
 }
 
+// RepositoryIndex returns the cached index of a repository previously registered with WithRepository
+func RepositoryIndex(ctx context.Context, name string) (*repo.IndexFile, error) {
+	settings := settingsFromContext(ctx)
+	return repo.LoadIndexFile(filepath.Join(settings.RepositoryCache, helmpath.CacheIndexFile(name)))
+}
+
+// LoadIndex downloads and parses the index of the chart repository at repoUrl,
+// without registering the repository in the context
+func LoadIndex(ctx context.Context, repoUrl string) (*repo.IndexFile, error) {
+	cachedir, err := os.MkdirTemp("", "helmindex-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(cachedir)
+
+	logger.FromContext(ctx).Debugf("downloading repository index from %s", repoUrl)
+	entry := &repo.Entry{
+		Name: HelmRepoName + "-index",
+		URL:  repoUrl,
+	}
+	r, err := repo.NewChartRepository(entry, getter.All(cli.New()))
+	if err != nil {
+		return nil, err
+	}
+
+	r.CachePath = cachedir
+	path, err := r.DownloadIndexFile()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.LoadIndexFile(path)
+}
+
 func GetRepoUrl(version string, repoUrl string) string {
 	if repoUrl == "" {
 		repoUrl = fmt.Sprintf("%s/%s", HelmDefaultRepo, version)
@@ -0,0 +1,135 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"strings"
+	"time"
+)
+
+type cdxBom struct {
+	BomFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies,omitempty"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     []cdxTool    `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTool struct {
+	Vendor  string `json:"vendor"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type cdxComponent struct {
+	BomRef             string           `json:"bom-ref"`
+	Type               string           `json:"type"`
+	Name               string           `json:"name"`
+	Version            string           `json:"version,omitempty"`
+	Purl               string           `json:"purl,omitempty"`
+	ExternalReferences []cdxExternalRef `json:"externalReferences,omitempty"`
+	Properties         []cdxProperty    `json:"properties,omitempty"`
+	Components         []cdxComponent   `json:"components,omitempty"`
+}
+
+type cdxExternalRef struct {
+	Type string `json:"type"`
+	Url  string `json:"url"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// cycloneDX converts the inventory to a CycloneDX 1.4 BOM. Components nest the container images they use,
+// while CRDs are listed as properties as CycloneDX has no dedicated type for them.
+func (inv *Inventory) cycloneDX() cdxBom {
+	bom := cdxBom{
+		BomFormat:    "CycloneDX",
+		SpecVersion:  "1.4",
+		SerialNumber: "urn:uuid:" + inv.serial().String(),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: inv.Timestamp.UTC().Format(time.RFC3339),
+			Tools:     []cdxTool{{Vendor: "Karavel", Name: "karavel", Version: inv.Tool}},
+			Component: cdxComponent{
+				BomRef:  "platform",
+				Type:    "application",
+				Name:    platformName,
+				Version: inv.Platform,
+			},
+		},
+		Components: make([]cdxComponent, 0, len(inv.Components)),
+	}
+
+	for _, c := range inv.Components {
+		ref := "component:" + c.Name
+		cc := cdxComponent{
+			BomRef:             ref,
+			Type:               "application",
+			Name:               c.Name,
+			Version:            c.Version,
+			ExternalReferences: []cdxExternalRef{{Type: "distribution", Url: c.Repository}},
+			Properties:         []cdxProperty{{Name: "karavel:chart", Value: c.Chart}},
+		}
+
+		if c.Namespace != "" {
+			cc.Properties = append(cc.Properties, cdxProperty{Name: "karavel:namespace", Value: c.Namespace})
+		}
+
+		for _, crd := range c.CRDs {
+			cc.Properties = append(cc.Properties,
+				cdxProperty{Name: "karavel:crd", Value: crd.Name},
+				cdxProperty{Name: "karavel:crd:" + crd.Name + ":versions", Value: strings.Join(crd.Versions, ",")},
+			)
+		}
+
+		for _, image := range c.Images {
+			img := parseImage(image)
+			cc.Components = append(cc.Components, cdxComponent{
+				BomRef:  ref + "/image:" + image,
+				Type:    "container",
+				Name:    img.name,
+				Version: img.version,
+				Purl:    img.purl,
+			})
+		}
+		bom.Components = append(bom.Components, cc)
+
+		if len(c.Dependencies) > 0 {
+			dep := cdxDependency{Ref: ref}
+			for _, d := range c.Dependencies {
+				dep.DependsOn = append(dep.DependsOn, "component:"+d)
+			}
+			bom.Dependencies = append(bom.Dependencies, dep)
+		}
+	}
+
+	return bom
+}
@@ -0,0 +1,81 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/karavel-io/cli/internal/oci"
+)
+
+const (
+	FormatCycloneDX = "cyclonedx"
+	FormatSPDX      = "spdx"
+)
+
+const platformName = "karavel-container-platform"
+
+// Write encodes the inventory as a JSON document in the given format
+func (inv *Inventory) Write(w io.Writer, format string) error {
+	var doc any
+	switch format {
+	case FormatCycloneDX:
+		doc = inv.cycloneDX()
+	case FormatSPDX:
+		doc = inv.spdx()
+	default:
+		return fmt.Errorf("unsupported inventory format '%s', must be one of %s, %s", format, FormatCycloneDX, FormatSPDX)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+type imageInfo struct {
+	name    string
+	version string
+	purl    string
+}
+
+// parseImage splits an image into its name and version. The purl is only set for images pinned
+// to a digest, as the OCI purl type requires one.
+func parseImage(image string) imageInfo {
+	ref, err := oci.ParseReference(image)
+	if err != nil {
+		return imageInfo{name: image}
+	}
+
+	info := imageInfo{
+		name:    ref.Registry + "/" + ref.Repository,
+		version: ref.Tag,
+	}
+	if ref.Digest == "" {
+		return info
+	}
+
+	if info.version == "" {
+		info.version = ref.Digest
+	}
+	info.purl = fmt.Sprintf("pkg:oci/%s@%s?repository_url=%s", path.Base(ref.Repository), strings.Replace(ref.Digest, ":", "%3A", 1), info.name)
+	if ref.Tag != "" {
+		info.purl += "&tag=" + ref.Tag
+	}
+	return info
+}
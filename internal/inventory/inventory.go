@@ -0,0 +1,133 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package inventory describes the components, container images and CRDs of a rendered platform,
+// and exports them as CycloneDX or SPDX documents.
+package inventory
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/karavel-io/cli/internal/helmw"
+	"github.com/karavel-io/cli/internal/postrender"
+
+	"github.com/google/uuid"
+)
+
+// Inventory lists the components of a Karavel Container Platform release
+type Inventory struct {
+	// Platform is the Karavel Container Platform version
+	Platform   string      `json:"platform"`
+	Components []Component `json:"components"`
+	// Tool is the version of the CLI that generated the inventory
+	Tool string `json:"-"`
+	// Timestamp is the generation time of the inventory
+	Timestamp time.Time `json:"-"`
+}
+
+// Component is a rendered component and the resources it ships
+type Component struct {
+	Name         string   `json:"name"`
+	Chart        string   `json:"chart"`
+	Version      string   `json:"version"`
+	Repository   string   `json:"repository"`
+	Namespace    string   `json:"namespace,omitempty"`
+	Dependencies []string `json:"dependencies,omitempty"`
+	Images       []string `json:"images"`
+	CRDs         []CRD    `json:"crds"`
+}
+
+// CRD is a CustomResourceDefinition shipped by a component
+type CRD struct {
+	Name     string   `json:"name"`
+	Group    string   `json:"group"`
+	Kind     string   `json:"kind"`
+	Versions []string `json:"versions"`
+}
+
+// Collect fills the component images and CRDs from its rendered documents
+func (c *Component) Collect(docs []helmw.YamlDoc) {
+	seen := make(map[string]bool)
+	c.Images = make([]string, 0)
+	c.CRDs = make([]CRD, 0)
+	for _, doc := range docs {
+		postrender.WalkImages(doc, func(image string) string {
+			if !seen[image] {
+				seen[image] = true
+				c.Images = append(c.Images, image)
+			}
+			return image
+		})
+
+		if crd, ok := parseCRD(doc); ok {
+			c.CRDs = append(c.CRDs, crd)
+		}
+	}
+
+	sort.Strings(c.Images)
+	sort.Slice(c.CRDs, func(i, j int) bool {
+		return c.CRDs[i].Name < c.CRDs[j].Name
+	})
+}
+
+func parseCRD(doc helmw.YamlDoc) (CRD, bool) {
+	if doc["kind"] != "CustomResourceDefinition" {
+		return CRD{}, false
+	}
+
+	var crd CRD
+	meta := asMap(doc["metadata"])
+	spec := asMap(doc["spec"])
+	names := asMap(spec["names"])
+	crd.Name, _ = meta["name"].(string)
+	crd.Group, _ = spec["group"].(string)
+	crd.Kind, _ = names["kind"].(string)
+
+	// apiextensions.k8s.io/v1beta1 CRDs may declare a single version instead of a list
+	versions := make(map[string]bool)
+	addVersion := func(v string) {
+		if v != "" && !versions[v] {
+			versions[v] = true
+			crd.Versions = append(crd.Versions, v)
+		}
+	}
+
+	v, _ := spec["version"].(string)
+	addVersion(v)
+	vv, _ := spec["versions"].([]any)
+	for _, v := range vv {
+		n, _ := asMap(v)["name"].(string)
+		addVersion(n)
+	}
+	return crd, true
+}
+
+func asMap(v any) map[string]any {
+	switch m := v.(type) {
+	case helmw.YamlDoc:
+		return m
+	case map[string]any:
+		return m
+	default:
+		return nil
+	}
+}
+
+// serial derives a stable UUID from the inventory content, so that the same platform always produces the same document
+func (inv *Inventory) serial() uuid.UUID {
+	b, _ := json.Marshal(inv)
+	return uuid.NewSHA1(uuid.NameSpaceURL, append([]byte("https://karavel.io/inventory/"), b...))
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/karavel-io/cli/internal/helmw"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const manifests = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: certificates.cert-manager.io
+spec:
+  group: cert-manager.io
+  names:
+    kind: Certificate
+  versions:
+    - name: v1
+    - name: v1alpha2
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: issuers.cert-manager.io
+spec:
+  group: cert-manager.io
+  version: v1alpha2
+  names:
+    kind: Issuer
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cert-manager
+spec:
+  template:
+    spec:
+      containers:
+        - name: controller
+          image: quay.io/jetstack/cert-manager-controller:v1.7.1@sha256:abcd
+        - name: sidecar
+          image: busybox
+`
+
+func testInventory(t *testing.T) Inventory {
+	var docs []helmw.YamlDoc
+	dec := yaml.NewDecoder(strings.NewReader(manifests))
+	for {
+		var doc helmw.YamlDoc
+		if err := dec.Decode(&doc); err != nil {
+			break
+		}
+		docs = append(docs, doc)
+	}
+
+	c := Component{
+		Name:         "cert-manager",
+		Chart:        "cert-manager",
+		Version:      "0.1.0",
+		Repository:   "https://charts.karavel.io/2022.1",
+		Namespace:    "cert-manager",
+		Dependencies: []string{"prometheus"},
+	}
+	c.Collect(docs)
+
+	return Inventory{
+		Platform:   "2022.1",
+		Components: []Component{c},
+		Tool:       "v0.5.0",
+		Timestamp:  time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestCollect(t *testing.T) {
+	c := testInventory(t).Components[0]
+	assert.Equal(t, []string{"busybox", "quay.io/jetstack/cert-manager-controller:v1.7.1@sha256:abcd"}, c.Images)
+	assert.Equal(t, []CRD{
+		{Name: "certificates.cert-manager.io", Group: "cert-manager.io", Kind: "Certificate", Versions: []string{"v1", "v1alpha2"}},
+		{Name: "issuers.cert-manager.io", Group: "cert-manager.io", Kind: "Issuer", Versions: []string{"v1alpha2"}},
+	}, c.CRDs)
+}
+
+func TestCycloneDX(t *testing.T) {
+	inv := testInventory(t)
+	bom := inv.cycloneDX()
+
+	assert.Equal(t, "2022-03-01T10:00:00Z", bom.Metadata.Timestamp)
+	require.Len(t, bom.Components, 1)
+	c := bom.Components[0]
+	assert.Equal(t, "component:cert-manager", c.BomRef)
+	assert.Contains(t, c.Properties, cdxProperty{Name: "karavel:crd", Value: "issuers.cert-manager.io"})
+	require.Len(t, c.Components, 2)
+	assert.Equal(t, cdxComponent{
+		BomRef:  "component:cert-manager/image:busybox",
+		Type:    "container",
+		Name:    "docker.io/library/busybox",
+		Version: "latest",
+	}, c.Components[0])
+	assert.Equal(t, "pkg:oci/cert-manager-controller@sha256%3Aabcd?repository_url=quay.io/jetstack/cert-manager-controller&tag=v1.7.1", c.Components[1].Purl)
+	assert.Equal(t, []cdxDependency{{Ref: "component:cert-manager", DependsOn: []string{"component:prometheus"}}}, bom.Dependencies)
+
+	// the serial number only depends on the inventory content
+	inv.Timestamp = time.Now()
+	assert.Equal(t, bom.SerialNumber, inv.cycloneDX().SerialNumber)
+}
+
+func TestSPDX(t *testing.T) {
+	inv := testInventory(t)
+	var buf bytes.Buffer
+	require.NoError(t, inv.Write(&buf, FormatSPDX))
+
+	var doc spdxDocument
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, "SPDX-2.2", doc.SpdxVersion)
+	require.Len(t, doc.Packages, 3)
+	assert.Equal(t, "SPDXRef-Component-cert-manager", doc.Packages[0].SPDXID)
+	assert.Contains(t, doc.Packages[0].Comment, "certificates.cert-manager.io (v1, v1alpha2)")
+	assert.Equal(t, "SPDXRef-Image-cert-manager-busybox", doc.Packages[1].SPDXID)
+	assert.Equal(t, []spdxRelationship{
+		{SpdxElementId: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSpdxElement: "SPDXRef-Component-cert-manager"},
+		{SpdxElementId: "SPDXRef-Component-cert-manager", RelationshipType: "CONTAINS", RelatedSpdxElement: doc.Packages[1].SPDXID},
+		{SpdxElementId: "SPDXRef-Component-cert-manager", RelationshipType: "CONTAINS", RelatedSpdxElement: doc.Packages[2].SPDXID},
+		{SpdxElementId: "SPDXRef-Component-cert-manager", RelationshipType: "DEPENDS_ON", RelatedSpdxElement: "SPDXRef-Component-prometheus"},
+	}, doc.Relationships)
+
+	assert.Error(t, inv.Write(&buf, "xml"))
+}
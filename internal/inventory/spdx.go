@@ -0,0 +1,155 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const noAssertion = "NOASSERTION"
+
+type spdxDocument struct {
+	SpdxVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	Comment          string            `json:"comment,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SpdxElementId      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSpdxElement string `json:"relatedSpdxElement"`
+}
+
+// spdxInvalidChars matches the characters not allowed in SPDX identifiers
+var spdxInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+func spdxId(kind string, name string) string {
+	return fmt.Sprintf("SPDXRef-%s-%s", kind, spdxInvalidChars.ReplaceAllString(name, "-"))
+}
+
+// spdx converts the inventory to an SPDX 2.2 document. Components and images are packages,
+// related by CONTAINS and DEPENDS_ON relationships, while CRDs are listed in the component comment.
+func (inv *Inventory) spdx() spdxDocument {
+	name := fmt.Sprintf("%s-%s", platformName, inv.Platform)
+	doc := spdxDocument{
+		SpdxVersion:       "SPDX-2.2",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              name,
+		DocumentNamespace: fmt.Sprintf("https://karavel.io/spdx/%s-%s", name, inv.serial()),
+		CreationInfo: spdxCreationInfo{
+			Created:  inv.Timestamp.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: karavel-" + inv.Tool},
+		},
+		Packages:      make([]spdxPackage, 0),
+		Relationships: make([]spdxRelationship, 0),
+	}
+
+	for _, c := range inv.Components {
+		id := spdxId("Component", c.Name)
+		comment := fmt.Sprintf("Karavel component rendered from Helm chart %s", c.Chart)
+		if c.Namespace != "" {
+			comment += fmt.Sprintf(" in namespace %s", c.Namespace)
+		}
+		if len(c.CRDs) > 0 {
+			var crds []string
+			for _, crd := range c.CRDs {
+				crds = append(crds, fmt.Sprintf("%s (%s)", crd.Name, strings.Join(crd.Versions, ", ")))
+			}
+			comment += ". CRDs: " + strings.Join(crds, "; ")
+		}
+
+		doc.Packages = append(doc.Packages, spdxPackage{
+			SPDXID:           id,
+			Name:             c.Name,
+			VersionInfo:      c.Version,
+			DownloadLocation: c.Repository,
+			LicenseConcluded: noAssertion,
+			LicenseDeclared:  noAssertion,
+			CopyrightText:    noAssertion,
+			Comment:          comment,
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SpdxElementId:      doc.SPDXID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSpdxElement: id,
+		})
+
+		for _, image := range c.Images {
+			img := parseImage(image)
+			imgId := spdxId("Image", c.Name+"-"+image)
+			pkg := spdxPackage{
+				SPDXID:           imgId,
+				Name:             img.name,
+				VersionInfo:      img.version,
+				DownloadLocation: noAssertion,
+				LicenseConcluded: noAssertion,
+				LicenseDeclared:  noAssertion,
+				CopyrightText:    noAssertion,
+			}
+			if img.purl != "" {
+				pkg.ExternalRefs = []spdxExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: img.purl}}
+			}
+			doc.Packages = append(doc.Packages, pkg)
+			doc.Relationships = append(doc.Relationships, spdxRelationship{
+				SpdxElementId:      id,
+				RelationshipType:   "CONTAINS",
+				RelatedSpdxElement: imgId,
+			})
+		}
+
+		for _, d := range c.Dependencies {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{
+				SpdxElementId:      id,
+				RelationshipType:   "DEPENDS_ON",
+				RelatedSpdxElement: spdxId("Component", d),
+			})
+		}
+	}
+
+	return doc
+}
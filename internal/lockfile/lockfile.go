@@ -0,0 +1,133 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lockfile
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+const Filename = "karavel.lock"
+
+const header = "# This file is generated by Karavel and records what was resolved during the last render.\n# Do not edit it manually.\n"
+
+// Lockfile records the component versions that were resolved during the last render
+type Lockfile struct {
+	Version    string               `yaml:"version"`
+	Components map[string]Component `yaml:"components"`
+	// Images maps the container images pinned during the last render to their digest
+	Images map[string]string `yaml:"images,omitempty"`
+}
+
+type Component struct {
+	Component string `yaml:"component"`
+	Version   string `yaml:"version"`
+	Unstable  bool   `yaml:"unstable,omitempty"`
+}
+
+func New(version string) Lockfile {
+	return Lockfile{
+		Version:    version,
+		Components: map[string]Component{},
+	}
+}
+
+// Read loads a lockfile from disk. A missing file results in an empty lockfile.
+func Read(filename string) (Lockfile, error) {
+	l := New("")
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return l, err
+	}
+
+	if err := yaml.Unmarshal(b, &l); err != nil {
+		return l, fmt.Errorf("failed to decode lockfile %s: %w", filename, err)
+	}
+
+	if l.Components == nil {
+		l.Components = map[string]Component{}
+	}
+	return l, nil
+}
+
+func (l *Lockfile) Write(filename string) error {
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(l); err != nil {
+		return err
+	}
+
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, buf.Bytes(), 0o644)
+}
+
+// Change describes how a component changed between two lockfiles.
+// From is empty for added components, To is empty for removed ones.
+type Change struct {
+	Name string
+	From string
+	To   string
+}
+
+func (c Change) String() string {
+	switch {
+	case c.From == "":
+		return fmt.Sprintf("%s: added (%s)", c.Name, c.To)
+	case c.To == "":
+		return fmt.Sprintf("%s: removed (%s)", c.Name, c.From)
+	default:
+		return fmt.Sprintf("%s: %s -> %s", c.Name, c.From, c.To)
+	}
+}
+
+// Diff lists the component version changes from old to l, sorted by component name
+func (l *Lockfile) Diff(old Lockfile) []Change {
+	var changes []Change
+	for name, c := range l.Components {
+		o, ok := old.Components[name]
+		if !ok {
+			changes = append(changes, Change{Name: name, To: c.Version})
+			continue
+		}
+
+		if o.Version != c.Version {
+			changes = append(changes, Change{Name: name, From: o.Version, To: c.Version})
+		}
+	}
+
+	for name, o := range old.Components {
+		if _, ok := l.Components[name]; !ok {
+			changes = append(changes, Change{Name: name, From: o.Version})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
@@ -0,0 +1,65 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lockfile
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadWrite(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), Filename)
+
+	l, err := Read(filename)
+	require.NoError(t, err)
+	assert.Empty(t, l.Components)
+
+	l = New("2022.1")
+	l.Components["argocd"] = Component{Component: "argocd", Version: "0.3.0"}
+	l.Components["dex"] = Component{Component: "dex", Version: "0.2.0-rc.1", Unstable: true}
+	l.Images = map[string]string{"ghcr.io/dexidp/dex:v2.31.0": "sha256:0123"}
+	require.NoError(t, l.Write(filename))
+
+	read, err := Read(filename)
+	require.NoError(t, err)
+	assert.Equal(t, l, read)
+}
+
+func TestDiff(t *testing.T) {
+	old := New("2022.1")
+	old.Components["argocd"] = Component{Component: "argocd", Version: "0.3.0"}
+	old.Components["dex"] = Component{Component: "dex", Version: "0.2.0"}
+	old.Components["grafana"] = Component{Component: "grafana", Version: "1.0.0"}
+
+	l := New("2022.2")
+	l.Components["argocd"] = Component{Component: "argocd", Version: "0.4.0"}
+	l.Components["cert-manager"] = Component{Component: "cert-manager", Version: "1.1.0"}
+	l.Components["grafana"] = Component{Component: "grafana", Version: "1.0.0"}
+
+	changes := l.Diff(old)
+	var lines []string
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+
+	assert.Equal(t, []string{
+		"argocd: 0.3.0 -> 0.4.0",
+		"cert-manager: added (1.1.0)",
+		"dex: removed (0.2.0)",
+	}, lines)
+}
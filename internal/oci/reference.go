@@ -0,0 +1,82 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oci resolves container image references against OCI distribution registries.
+package oci
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultRegistry is the registry of images without an explicit registry host
+const DefaultRegistry = "docker.io"
+
+// Reference is a parsed container image reference
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// SplitImage returns the registry host of an image and the remaining reference,
+// normalizing Docker Hub images to their fully qualified form.
+func SplitImage(image string) (string, string) {
+	i := strings.Index(image, "/")
+	if i > 0 {
+		host := image[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			return host, image[i+1:]
+		}
+		return DefaultRegistry, image
+	}
+	return DefaultRegistry, "library/" + image
+}
+
+// ParseReference parses an image reference. The tag defaults to latest if neither a tag nor a digest is set.
+func ParseReference(image string) (Reference, error) {
+	var ref Reference
+	ref.Registry, ref.Repository = SplitImage(image)
+
+	if i := strings.Index(ref.Repository, "@"); i >= 0 {
+		ref.Digest = ref.Repository[i+1:]
+		ref.Repository = ref.Repository[:i]
+	}
+
+	if i := strings.LastIndex(ref.Repository, ":"); i > strings.LastIndex(ref.Repository, "/") {
+		ref.Tag = ref.Repository[i+1:]
+		ref.Repository = ref.Repository[:i]
+	}
+
+	if ref.Repository == "" || strings.HasSuffix(ref.Repository, "/") {
+		return ref, fmt.Errorf("invalid image reference '%s'", image)
+	}
+
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+	return ref, nil
+}
+
+func (r Reference) String() string {
+	s := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
@@ -0,0 +1,50 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitImage(t *testing.T) {
+	for image, want := range map[string][2]string{
+		"nginx":                          {"docker.io", "library/nginx"},
+		"bitnami/redis:6":                {"docker.io", "bitnami/redis:6"},
+		"localhost/app":                  {"localhost", "app"},
+		"registry.local:5000/a/b":        {"registry.local:5000", "a/b"},
+		"quay.io/karavel/cli@sha256:abc": {"quay.io", "karavel/cli@sha256:abc"},
+	} {
+		host, path := SplitImage(image)
+		assert.Equal(t, want, [2]string{host, path}, image)
+	}
+}
+func TestParseReference(t *testing.T) {
+	for image, want := range map[string]Reference{
+		"nginx":                               {Registry: "docker.io", Repository: "library/nginx", Tag: "latest"},
+		"registry.local:5000/a/b:1.0":         {Registry: "registry.local:5000", Repository: "a/b", Tag: "1.0"},
+		"quay.io/karavel/cli:main@sha256:abc": {Registry: "quay.io", Repository: "karavel/cli", Tag: "main", Digest: "sha256:abc"},
+		"ghcr.io/dexidp/dex@sha256:abc":       {Registry: "ghcr.io", Repository: "dexidp/dex", Digest: "sha256:abc"},
+	} {
+		ref, err := ParseReference(image)
+		require.NoError(t, err, image)
+		assert.Equal(t, want, ref, image)
+	}
+
+	_, err := ParseReference("quay.io/")
+	assert.Error(t, err)
+}
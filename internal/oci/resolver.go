@@ -0,0 +1,225 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// manifestTypes are the manifest media types accepted when resolving a digest.
+// Indexes come first so that multi-arch images are pinned to their index.
+var manifestTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// registryHosts maps registry names to the host serving their API
+var registryHosts = map[string]string{
+	DefaultRegistry: "registry-1.docker.io",
+}
+
+// Resolver resolves image tags to manifest digests using the OCI distribution API.
+// Registries requiring a bearer token are accessed anonymously.
+type Resolver struct {
+	Client *http.Client
+	// Insecure lists the registry hosts served over plain HTTP, along with the ones on localhost
+	Insecure []string
+}
+
+// Resolve returns the manifest digest of an image
+func (r *Resolver) Resolve(ctx context.Context, image string) (string, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+
+	host := ref.Registry
+	if h, ok := registryHosts[host]; ok {
+		host = h
+	}
+	u := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", r.scheme(host), host, ref.Repository, ref.Tag)
+
+	res, err := r.manifest(ctx, http.MethodHead, u, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve image '%s': %w", image, err)
+	}
+	_ = res.Body.Close()
+
+	var token string
+	if res.StatusCode == http.StatusUnauthorized {
+		token, err = r.token(ctx, res.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve image '%s': %w", image, err)
+		}
+
+		res, err = r.manifest(ctx, http.MethodHead, u, token)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve image '%s': %w", image, err)
+		}
+		_ = res.Body.Close()
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to resolve image '%s': registry returned %s", image, res.Status)
+	}
+
+	if d := res.Header.Get("Docker-Content-Digest"); d != "" {
+		return d, nil
+	}
+
+	// the digest header is optional, fall back to hashing the manifest
+	res, err = r.manifest(ctx, http.MethodGet, u, token)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve image '%s': %w", image, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to resolve image '%s': registry returned %s", image, res.Status)
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, res.Body); err != nil {
+		return "", fmt.Errorf("failed to resolve image '%s': %w", image, err)
+	}
+	return fmt.Sprintf("sha256:%x", h.Sum(nil)), nil
+}
+
+// scheme returns the URL scheme of a registry host, like the Docker daemon plain HTTP is only used
+// for registries on localhost or explicitly marked as insecure
+func (r *Resolver) scheme(host string) string {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	if hostname == "localhost" {
+		return "http"
+	}
+
+	for _, i := range r.Insecure {
+		if i == host || i == hostname {
+			return "http"
+		}
+	}
+	return "https"
+}
+
+func (r *Resolver) client() *http.Client {
+	if r.Client != nil {
+		return r.Client
+	}
+	return http.DefaultClient
+}
+
+func (r *Resolver) manifest(ctx context.Context, method string, u string, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", strings.Join(manifestTypes, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return r.client().Do(req)
+}
+
+// token requests an anonymous bearer token following the challenge returned by the registry
+func (r *Resolver) token(ctx context.Context, challenge string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	if !strings.EqualFold(scheme, "bearer") || params["realm"] == "" {
+		return "", fmt.Errorf("registry requires unsupported authentication '%s'", challenge)
+	}
+
+	q := url.Values{}
+	for _, k := range []string{"service", "scope"} {
+		if v := params[k]; v != "" {
+			q.Set(k, v)
+		}
+	}
+
+	u := params["realm"]
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := r.client().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request registry token: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to request registry token: %s", res.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode registry token: %w", err)
+	}
+
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
+
+// parseChallenge parses a WWW-Authenticate header such as: Bearer realm="https://auth",service="registry"
+func parseChallenge(h string) (string, map[string]string) {
+	params := make(map[string]string)
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(h), " ")
+	for rest = strings.TrimLeft(rest, " ,"); rest != ""; rest = strings.TrimLeft(rest, " ,") {
+		k, v, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+
+		var val string
+		if strings.HasPrefix(v, `"`) {
+			end := strings.Index(v[1:], `"`)
+			if end < 0 {
+				break
+			}
+			val, rest = v[1:end+1], v[end+2:]
+		} else {
+			val, rest, _ = strings.Cut(v, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(k))] = val
+	}
+	return scheme, params
+}
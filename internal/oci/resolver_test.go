@@ -0,0 +1,110 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// newRegistry serves the manifest of karavel/cli:1.0, requiring an anonymous bearer token
+func newRegistry(t *testing.T, tls bool) *httptest.Server {
+	var srv *httptest.Server
+	srv = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			assert.Equal(t, "repository:karavel/cli:pull", r.URL.Query().Get("scope"))
+			_, _ = fmt.Fprint(w, `{"token":"t0k3n"}`)
+		case r.Header.Get("Authorization") != "Bearer t0k3n":
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test",scope="repository:karavel/cli:pull"`, srv.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/v2/karavel/cli/manifests/1.0":
+			assert.Contains(t, r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json")
+			if r.Method == http.MethodHead {
+				w.Header().Set("Docker-Content-Digest", testDigest)
+			}
+		case r.URL.Path == "/v2/karavel/cli/manifests/nodigest":
+			_, _ = fmt.Fprint(w, "{}")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	if tls {
+		srv.StartTLS()
+	} else {
+		srv.Start()
+	}
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestResolve(t *testing.T) {
+	srv := newRegistry(t, true)
+	host := strings.TrimPrefix(srv.URL, "https://")
+	r := &Resolver{Client: srv.Client()}
+
+	d, err := r.Resolve(context.Background(), host+"/karavel/cli:1.0")
+	require.NoError(t, err)
+	assert.Equal(t, testDigest, d)
+
+	// sha256 of "{}"
+	d, err = r.Resolve(context.Background(), host+"/karavel/cli:nodigest")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a", d)
+
+	_, err = r.Resolve(context.Background(), host+"/karavel/cli:missing")
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestResolveInsecure(t *testing.T) {
+	srv := newRegistry(t, false)
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	_, err := (&Resolver{}).Resolve(context.Background(), host+"/karavel/cli:1.0")
+	assert.Error(t, err)
+
+	r := &Resolver{Insecure: []string{host}}
+	d, err := r.Resolve(context.Background(), host+"/karavel/cli:1.0")
+	require.NoError(t, err)
+	assert.Equal(t, testDigest, d)
+}
+
+func TestResolverScheme(t *testing.T) {
+	r := &Resolver{Insecure: []string{"registry.local"}}
+	assert.Equal(t, "http", r.scheme("localhost:5000"))
+	assert.Equal(t, "http", r.scheme("localhost"))
+	assert.Equal(t, "http", r.scheme("registry.local:5000"))
+	assert.Equal(t, "https", r.scheme("localhost.example.com"))
+	assert.Equal(t, "https", r.scheme("ghcr.io"))
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull,push"`)
+	assert.Equal(t, "Bearer", scheme)
+	assert.Equal(t, map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:library/nginx:pull,push",
+	}, params)
+}
@@ -19,16 +19,19 @@ import (
 	"context"
 	"encoding/csv"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
-	"sync"
 
 	"github.com/karavel-io/cli/internal/argo"
 	"github.com/karavel-io/cli/internal/helmw"
+	"github.com/karavel-io/cli/internal/postrender"
+	"github.com/karavel-io/cli/internal/secrets"
 	"github.com/karavel-io/cli/internal/utils"
 	"github.com/karavel-io/cli/internal/utils/predicate"
 	"github.com/karavel-io/cli/pkg/logger"
@@ -53,9 +56,11 @@ var reservedAnnotations = map[string]bool{
 }
 
 type Component struct {
-	name             string
-	component        string
-	namespace        string
+	name      string
+	component string
+	namespace string
+	// chartNamespace is the default namespace declared by the chart, used if namespace is empty
+	chartNamespace   string
 	version          string
 	bootstrap        bool
 	singleton        bool
@@ -63,6 +68,13 @@ type Component struct {
 	integrationsDeps map[string][]string
 	integrations     map[string]bool
 	jsonParams       string
+	secretParams     []string
+	secrets          *secrets.Options
+	kustomize        *utils.KustomizeOptions
+	postRenderers    []postrender.PostRenderer
+	mirrors          *postrender.Registry
+	digests          *postrender.PinDigests
+	layout           string
 	unstable         bool
 }
 
@@ -144,10 +156,44 @@ func (c *Component) IsBootstrap() bool {
 	return c.bootstrap
 }
 
+func (c *Component) IsSingleton() bool {
+	return c.singleton
+}
+
+func (c *Component) Dependencies() []string {
+	return c.dependencies
+}
+
+// IntegrationDependencies returns the components each integration requires to be enabled
+func (c *Component) IntegrationDependencies() map[string][]string {
+	return c.integrationsDeps
+}
+
+func (c *Component) IsUnstable() bool {
+	return c.unstable
+}
+
 func (c *Component) Params() string {
 	return c.jsonParams
 }
 
+// redacted replaces secret values in RedactedParams
+const redacted = "<redacted>"
+
+// RedactedParams returns the params with secret values replaced, to be safely logged
+func (c *Component) RedactedParams() string {
+	jp := c.jsonParams
+	for _, p := range c.secretParams {
+		if j, err := sjson.Set(jp, p, redacted); err == nil {
+			jp = j
+		} else {
+			// never leak the params if a secret cannot be redacted
+			return redacted
+		}
+	}
+	return jp
+}
+
 func (c *Component) GetParam(path string) gjson.Result {
 	return gjson.Get(c.jsonParams, path)
 }
@@ -160,26 +206,160 @@ func (c *Component) DebugLabel() string {
 	return fmt.Sprintf("'%s' %s%s", c.ComponentName(), c.Version(), withAlias)
 }
 
-type routineRes struct {
-	filename string
-	err      error
+// Image is a container image used by a component
+type Image struct {
+	Name string `json:"name"`
+	// Mirror is the rewritten image reference, if a registry mirror is configured for it
+	Mirror string `json:"mirror,omitempty"`
 }
 
+const componentErrorFormat = "failed to render component '%s' v%s: %w"
+
 func (c *Component) Render(ctx context.Context, log logger.Logger, outdir string) error {
-	const errorFormat = "failed to render component '%s' v%s: %w"
+	docs, err := c.Template(ctx, log)
+	if err != nil {
+		return err
+	}
 
-	if err := os.RemoveAll(outdir); err != nil {
-		return fmt.Errorf(errorFormat, c.name, c.version, err)
+	return c.Write(log, outdir, docs)
+}
+
+// Template renders the component chart, runs its post-renderers and rewrites its images to registry mirrors
+// and digests, returning the documents to be written. Kustomize options are left to the component kustomization file
+func (c *Component) Template(ctx context.Context, log logger.Logger) ([]helmw.YamlDoc, error) {
+	docs, err := c.template(ctx, log)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := os.MkdirAll(outdir, 0o755); err != nil {
-		return fmt.Errorf(errorFormat, c.name, c.version, err)
+	if c.mirrors != nil {
+		log.Debugf("component %s: rewriting images to registry mirrors", c.DebugLabel())
+		docs, err = c.mirrors.Run(ctx, docs)
+		if err != nil {
+			return nil, fmt.Errorf(componentErrorFormat, c.name, c.version, err)
+		}
+	}
+
+	if c.digests != nil {
+		log.Debugf("component %s: pinning images to their digest", c.DebugLabel())
+		docs, err = c.digests.Run(ctx, docs)
+		if err != nil {
+			return nil, fmt.Errorf(componentErrorFormat, c.name, c.version, err)
+		}
+	}
+
+	return docs, nil
+}
+
+// Manifests returns the documents of the component as they are deployed, with its kustomize options applied
+// and its namespace set on the namespaced documents that do not declare one
+func (c *Component) Manifests(ctx context.Context, log logger.Logger) ([]helmw.YamlDoc, error) {
+	docs, err := c.manifests(ctx, log)
+	if err != nil {
+		return nil, err
+	}
+
+	// without an ArgoCD application to provide the destination namespace, it must be set on the documents themselves
+	ns := postrender.Namespace{Namespace: c.namespace, Default: true}
+	if ns.Namespace == "" {
+		ns.Namespace = c.chartNamespace
+	}
+	return ns.Run(ctx, docs)
+}
+
+func (c *Component) manifests(ctx context.Context, log logger.Logger) ([]helmw.YamlDoc, error) {
+	docs, err := c.Template(ctx, log)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.runKustomize(docs)
+}
+
+// runKustomize applies the component kustomize options to the documents
+func (c *Component) runKustomize(docs []helmw.YamlDoc) ([]helmw.YamlDoc, error) {
+	if c.kustomize == nil {
+		return docs, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	for _, doc := range docs {
+		if len(doc) == 0 {
+			continue
+		}
+		if err := enc.Encode(doc); err != nil {
+			return nil, fmt.Errorf(componentErrorFormat, c.name, c.version, err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf(componentErrorFormat, c.name, c.version, err)
 	}
 
+	out, err := utils.KustomizeResources(buf.Bytes(), c.kustomize)
+	if err != nil {
+		return nil, fmt.Errorf(componentErrorFormat, c.name, c.version, err)
+	}
+
+	docs = nil
+	dec := yaml.NewDecoder(bytes.NewReader(out))
+	for {
+		var doc helmw.YamlDoc
+		if err := dec.Decode(&doc); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf(componentErrorFormat, c.name, c.version, err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// Images lists the container images used by the component, sorted by name.
+// Kustomize image overrides are applied, as the images they set are the ones actually deployed
+func (c *Component) Images(ctx context.Context, log logger.Logger) ([]Image, error) {
+	docs, err := c.template(ctx, log)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err = c.runKustomize(docs)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	images := make([]Image, 0)
+	for _, doc := range docs {
+		postrender.WalkImages(doc, func(image string) string {
+			if !seen[image] {
+				seen[image] = true
+				img := Image{Name: image}
+				if c.mirrors != nil {
+					if m := c.mirrors.Rewrite(image); m != image {
+						img.Mirror = m
+					}
+				}
+				images = append(images, img)
+			}
+			return image
+		})
+	}
+
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].Name < images[j].Name
+	})
+	return images, nil
+}
+
+// template renders the component chart and runs its post-renderers and secrets transformation.
+// Registry mirrors, digest pinning, kustomize options and the default namespace are applied by the callers.
+func (c *Component) template(ctx context.Context, log logger.Logger) ([]helmw.YamlDoc, error) {
 	if no := c.NameOverride(); no != "" {
 		j, err := sjson.Set(c.jsonParams, "nameOverride", no)
 		if err != nil {
-			return fmt.Errorf(errorFormat, c.name, c.version, err)
+			return nil, fmt.Errorf(componentErrorFormat, c.name, c.version, err)
 		}
 
 		c.jsonParams = j
@@ -192,73 +372,117 @@ func (c *Component) Render(ctx context.Context, log logger.Logger, outdir string
 		Unstable:  c.unstable,
 	})
 	if err != nil {
-		return fmt.Errorf(errorFormat, c.name, c.version, err)
+		return nil, fmt.Errorf(componentErrorFormat, c.name, c.version, err)
 	}
 
-	log.Debugf("component %s: writing %d resources", c.DebugLabel(), len(docs))
-
-	var wg sync.WaitGroup
-	resch := make(chan routineRes)
+	if len(c.postRenderers) > 0 {
+		log.Debugf("component %s: running %d post-renderers", c.DebugLabel(), len(c.postRenderers))
+		docs, err = postrender.RunAll(ctx, c.postRenderers, docs)
+		if err != nil {
+			return nil, fmt.Errorf(componentErrorFormat, c.name, c.version, err)
+		}
+	}
 
-	for i, d := range docs {
-		if len(d) == 0 {
-			continue
+	if c.secrets != nil {
+		docs, err = c.transformSecrets(log, docs)
+		if err != nil {
+			return nil, fmt.Errorf(componentErrorFormat, c.name, c.version, err)
 		}
+	}
 
-		wg.Add(1)
-		go func(i int, doc helmw.YamlDoc) {
-			defer wg.Done()
+	return docs, nil
+}
 
-			var buf bytes.Buffer
-			enc := yaml.NewEncoder(&buf)
-			enc.SetIndent(2)
+// Write writes the documents to outdir, one file per resource, along with their kustomization file.
+// Any existing content of outdir is removed.
+func (c *Component) Write(log logger.Logger, outdir string, docs []helmw.YamlDoc) error {
+	files, err := manifestFiles(docs, c.namespace, c.layout)
+	if err != nil {
+		return fmt.Errorf(componentErrorFormat, c.name, c.version, err)
+	}
+
+	if err := os.RemoveAll(outdir); err != nil {
+		return fmt.Errorf(componentErrorFormat, c.name, c.version, err)
+	}
+
+	if err := os.MkdirAll(outdir, 0o755); err != nil {
+		return fmt.Errorf(componentErrorFormat, c.name, c.version, err)
+	}
+
+	log.Debugf("component %s: writing %d resources", c.DebugLabel(), len(docs))
+
+	// the files are written sequentially, components are already rendered in parallel
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		for _, doc := range f.docs {
 			if err := enc.Encode(&doc); err != nil {
-				resch <- routineRes{err: err}
-				return
+				return fmt.Errorf(componentErrorFormat, c.name, c.version, err)
 			}
+		}
 
-			if err := enc.Close(); err != nil {
-				resch <- routineRes{err: err}
-				return
-			}
+		if err := enc.Close(); err != nil {
+			return fmt.Errorf(componentErrorFormat, c.name, c.version, err)
+		}
 
-			k := strings.ToLower(doc["kind"].(string))
-			meta := doc["metadata"].(helmw.YamlDoc)
-			ns := ""
-			if meta["namespace"] != nil && meta["namespace"] != c.namespace {
-				ns = "-" + meta["namespace"].(string)
-			}
+		filename := filepath.Join(outdir, filepath.FromSlash(f.name))
+		log.Debugf("component %s writing file %s", c.DebugLabel(), path.Join(filepath.Base(outdir), f.name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			return fmt.Errorf(componentErrorFormat, c.name, c.version, err)
+		}
 
-			basename := fmt.Sprintf("%s-%s%s.yml", k, meta["name"], ns)
-			filename := filepath.Join(outdir, basename)
-			log.Debugf("component %s writing file %s", c.DebugLabel(), filepath.Join(filepath.Base(outdir), basename))
-			if err := ioutil.WriteFile(filename, buf.Bytes(), 0o655); err != nil {
-				resch <- routineRes{err: err}
-			}
+		if err := ioutil.WriteFile(filename, buf.Bytes(), 0o655); err != nil {
+			return fmt.Errorf(componentErrorFormat, c.name, c.version, err)
+		}
 
-			resch <- routineRes{filename: filepath.Base(basename)}
-		}(i, d)
+		names = append(names, f.name)
 	}
 
-	go func() {
-		wg.Wait()
-		close(resch)
-	}()
+	sort.Strings(names)
+	return utils.RenderKustomizeFile(outdir, names, predicate.StringFalse, c.kustomize)
+}
+
+// transformSecrets replaces the component Secrets with SealedSecrets or ExternalSecrets, according to the component config
+func (c *Component) transformSecrets(log logger.Logger, docs []helmw.YamlDoc) ([]helmw.YamlDoc, error) {
+	found := make(map[string]bool)
+	for i, doc := range docs {
+		if !secrets.IsSecret(doc) {
+			continue
+		}
+
+		meta, _ := doc["metadata"].(helmw.YamlDoc)
+		name, _ := meta["name"].(string)
+		if !c.secrets.Matches(name) {
+			continue
+		}
+		found[name] = true
+
+		log.Debugf("component %s: replacing secret %s with %s secret", c.DebugLabel(), name, c.secrets.Mode)
+		res, err := c.secrets.Transform(doc, c.namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		out := helmw.YamlDoc(res)
+		if m, ok := res["metadata"].(map[string]any); ok {
+			out["metadata"] = helmw.YamlDoc(m)
+		}
+		docs[i] = out
+	}
 
-	var files []string
-	for res := range resch {
-		if res.err != nil {
-			return fmt.Errorf(errorFormat, c.name, c.version, res.err)
+	for _, n := range c.secrets.Names {
+		if !found[n] {
+			log.Warnf("Component %s does not render secret %s", c.DebugLabel(), n)
 		}
-		files = append(files, res.filename)
 	}
 
-	sort.Strings(files)
-	return utils.RenderKustomizeFile(outdir, files, predicate.StringFalse)
+	return docs, nil
 }
 
-func (c *Component) RenderApplication(argoNs string, repoUrl string, path string, outfile string) error {
-	app := argo.NewApplication(c.name, c.namespace, argoNs, repoUrl, path)
+func (c *Component) RenderApplication(argoNs string, repoUrl string, revision string, path string, outfile string) error {
+	app := argo.NewApplication(c.name, c.namespace, argoNs, repoUrl, revision, path)
 	return app.Render(outfile)
 }
 
@@ -0,0 +1,59 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestNewComponentFromChartMetadata(t *testing.T) {
+	meta := &chart.Metadata{
+		Name:    "argocd",
+		Version: "0.3.0",
+		Annotations: map[string]string{
+			bootstrapAnnotation:    "true",
+			singletonAnnotation:    "true",
+			dependenciesAnnotation: "cert-manager, dex",
+			"monitoring.enabled":   "prometheus, grafana",
+		},
+	}
+
+	c, err := NewComponentFromChartMetadata(meta, false)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "argocd", c.Name())
+	assert.Equal(t, "0.3.0", c.Version())
+	assert.True(t, c.IsBootstrap())
+	assert.True(t, c.IsSingleton())
+	assert.False(t, c.IsUnstable())
+	assert.Equal(t, []string{"cert-manager", "dex"}, c.Dependencies())
+	assert.Equal(t, map[string][]string{
+		singletonAnnotation:  {"true"},
+		"monitoring.enabled": {"prometheus", "grafana"},
+	}, c.IntegrationDependencies())
+}
+
+func TestRedactedParams(t *testing.T) {
+	c := Component{
+		jsonParams:   `{"clientSecret":"s3cr3t","storage":{"accessKey":"a","bucket":"dex"},"a.b":"c"}`,
+		secretParams: []string{"clientSecret", "storage.accessKey", `a\.b`},
+	}
+
+	assert.JSONEq(t, `{"clientSecret":"<redacted>","storage":{"accessKey":"<redacted>","bucket":"dex"},"a.b":"<redacted>"}`, c.RedactedParams())
+	assert.Contains(t, c.Params(), "s3cr3t")
+}
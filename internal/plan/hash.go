@@ -0,0 +1,129 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/karavel-io/cli/internal/helmw"
+	"github.com/karavel-io/cli/internal/postrender"
+	"github.com/karavel-io/cli/internal/secrets"
+	"github.com/karavel-io/cli/internal/utils"
+	"github.com/karavel-io/cli/internal/version"
+)
+
+// hashInputs lists everything the rendered files of a component depend on
+type hashInputs struct {
+	Platform      string                    `json:"platform"`
+	Cli           string                    `json:"cli"`
+	Component     string                    `json:"component"`
+	Chart         string                    `json:"chart"`
+	Version       string                    `json:"version"`
+	Unstable      bool                      `json:"unstable"`
+	Namespace     string                    `json:"namespace"`
+	Values        string                    `json:"values"`
+	NameOverride  string                    `json:"nameOverride"`
+	Layout        string                    `json:"layout"`
+	Kustomize     *utils.KustomizeOptions   `json:"kustomize"`
+	PostRenderers []postrender.PostRenderer `json:"postRenderers"`
+	Commands      map[string]string         `json:"commands"`
+	Mirrors       *postrender.Registry      `json:"mirrors"`
+	PinDigests    bool                      `json:"pinDigests"`
+	Secrets       *secretsInputs            `json:"secrets"`
+}
+
+type secretsInputs struct {
+	Mode     secrets.Mode             `json:"mode"`
+	Names    []string                 `json:"names"`
+	Sealer   string                   `json:"sealer"`
+	External *secrets.ExternalOptions `json:"external"`
+}
+
+// InputHash returns a digest of everything the rendered files of the component depend on: its chart,
+// values, namespace and render options, along with the platform and CLI versions.
+// The content of local exec post-renderer commands is included, like patch files are, while the output of commands
+// looked up in PATH and the digests of pinned images are assumed not to change.
+func (c *Component) InputHash(ctx context.Context, platformVersion string) (string, error) {
+	chart, err := helmw.ChartDigest(ctx, c.component, c.version, c.unstable)
+	if err != nil {
+		return "", fmt.Errorf(componentErrorFormat, c.name, c.version, fmt.Errorf("failed to compute chart digest: %w", err))
+	}
+
+	v := version.Get()
+	in := hashInputs{
+		Platform:      platformVersion,
+		Cli:           v.Version + "+" + v.GitCommit,
+		Component:     c.component,
+		Chart:         chart,
+		Version:       c.version,
+		Unstable:      c.unstable,
+		Namespace:     c.namespace,
+		Values:        c.jsonParams,
+		NameOverride:  c.NameOverride(),
+		Layout:        c.layout,
+		Kustomize:     c.kustomize,
+		PostRenderers: c.postRenderers,
+		Mirrors:       c.mirrors,
+		PinDigests:    c.digests != nil,
+	}
+
+	in.Commands, err = commandDigests(c.postRenderers)
+	if err != nil {
+		return "", fmt.Errorf(componentErrorFormat, c.name, c.version, err)
+	}
+
+	if c.secrets != nil {
+		in.Secrets = &secretsInputs{
+			Mode:     c.secrets.Mode,
+			Names:    c.secrets.Names,
+			External: c.secrets.External,
+		}
+		if c.secrets.Sealer != nil {
+			in.Secrets.Sealer = c.secrets.Sealer.Fingerprint()
+		}
+	}
+
+	b, err := json.Marshal(in)
+	if err != nil {
+		return "", fmt.Errorf(componentErrorFormat, c.name, c.version, err)
+	}
+
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(b)), nil
+}
+
+// commandDigests returns the digests of the local commands run by exec post-renderers, indexed by path
+func commandDigests(prs []postrender.PostRenderer) (map[string]string, error) {
+	digests := make(map[string]string)
+	for _, pr := range prs {
+		e, ok := pr.(*postrender.Exec)
+		// commands without a path are looked up in PATH
+		if !ok || !strings.ContainsRune(filepath.ToSlash(e.Command), '/') {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(e.Command)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read post-renderer command: %w", err)
+		}
+		digests[e.Command] = fmt.Sprintf("sha256:%x", sha256.Sum256(b))
+	}
+	return digests, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/karavel-io/cli/internal/utils"
+	"github.com/karavel-io/cli/pkg/config"
+
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+)
+
+// newKustomizeOptions converts the component kustomize block, inlining patch files so that
+// the kustomization does not reference paths outside of the component directory
+func newKustomizeOptions(k *config.Kustomize) (*utils.KustomizeOptions, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	opts := &utils.KustomizeOptions{
+		CommonLabels:      k.CommonLabels,
+		CommonAnnotations: k.CommonAnnotations,
+	}
+
+	for _, img := range k.Images {
+		opts.Images = append(opts.Images, types.Image{
+			Name:    img.Name,
+			NewName: img.NewName,
+			NewTag:  img.NewTag,
+			Digest:  img.Digest,
+		})
+	}
+
+	for _, p := range k.Patches {
+		content := p.Content
+		if p.Path != "" {
+			b, err := ioutil.ReadFile(p.Path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read patch file: %w", err)
+			}
+			content = string(b)
+		}
+
+		patch := types.Patch{Patch: content}
+		if t := p.Target; t != nil {
+			patch.Target = &types.Selector{
+				ResId: resid.ResId{
+					Gvk:       resid.Gvk{Group: t.Group, Version: t.Version, Kind: t.Kind},
+					Name:      t.Name,
+					Namespace: t.Namespace,
+				},
+				LabelSelector:      t.LabelSelector,
+				AnnotationSelector: t.AnnotationSelector,
+			}
+		}
+		opts.Patches = append(opts.Patches, patch)
+	}
+
+	return opts, nil
+}
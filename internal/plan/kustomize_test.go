@@ -0,0 +1,74 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/karavel-io/cli/internal/utils"
+	"github.com/karavel-io/cli/internal/utils/predicate"
+	"github.com/karavel-io/cli/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+)
+
+func TestKustomizeOptions(t *testing.T) {
+	dir := t.TempDir()
+	patch := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: dex\nspec:\n  replicas: 3\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "patch.yml"), []byte(patch), 0o644))
+
+	opts, err := newKustomizeOptions(&config.Kustomize{
+		CommonAnnotations: map[string]string{"owner": "platform"},
+		Images:            []config.KustomizeImage{{Name: "dex", NewName: "mirror.local/dex"}},
+		Patches: []config.KustomizePatch{
+			{Path: filepath.Join(dir, "patch.yml")},
+			{Content: "- op: remove\n  path: /spec/replicas", Target: &config.KustomizeTarget{Kind: "Deployment", LabelSelector: "app=dex"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, opts.Patches, 2)
+	assert.Equal(t, patch, opts.Patches[0].Patch)
+	assert.Equal(t, "Deployment", opts.Patches[1].Target.Kind)
+	assert.Equal(t, []types.Image{{Name: "dex", NewName: "mirror.local/dex"}}, opts.Images)
+
+	out := t.TempDir()
+	require.NoError(t, utils.RenderKustomizeFile(out, []string{"deployment-dex.yml"}, predicate.StringFalse, opts))
+
+	b, err := ioutil.ReadFile(filepath.Join(out, "kustomization.yml"))
+	require.NoError(t, err)
+
+	var k types.Kustomization
+	require.NoError(t, k.Unmarshal(b))
+	assert.Equal(t, opts.Patches, k.Patches)
+	assert.Equal(t, opts.Images, k.Images)
+	assert.Equal(t, map[string]string{"owner": "platform"}, k.CommonAnnotations)
+
+	_, err = newKustomizeOptions(&config.Kustomize{Patches: []config.KustomizePatch{{Path: filepath.Join(dir, "missing.yml")}}})
+	assert.Error(t, err)
+}
+
+func TestKustomizeResources(t *testing.T) {
+	out, err := utils.KustomizeResources([]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: dex\ndata:\n  a: b\n"), &utils.KustomizeOptions{
+		CommonLabels: map[string]string{"team": "platform"},
+		Patches:      []types.Patch{{Patch: "- op: add\n  path: /data/c\n  value: d", Target: &types.Selector{ResId: resid.ResId{Gvk: resid.Gvk{Kind: "ConfigMap"}}}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\ndata:\n  a: b\n  c: d\nkind: ConfigMap\nmetadata:\n  labels:\n    team: platform\n  name: dex\n", string(out))
+}
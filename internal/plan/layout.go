@@ -0,0 +1,180 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/karavel-io/cli/internal/helmw"
+	"github.com/karavel-io/cli/internal/postrender"
+	"github.com/karavel-io/cli/pkg/config"
+)
+
+// singleFileName is the file holding all the resources of a component with the single-file layout
+const singleFileName = "resources.yml"
+
+// resourceId identifies a Kubernetes resource. The namespace is empty for cluster-scoped resources.
+type resourceId struct {
+	group     string
+	kind      string
+	name      string
+	namespace string
+}
+
+func (id resourceId) String() string {
+	gk := id.kind
+	if id.group != "" {
+		gk += "." + id.group
+	}
+	if id.namespace != "" {
+		return fmt.Sprintf("%s %s/%s", gk, id.namespace, id.name)
+	}
+	return fmt.Sprintf("%s %s", gk, id.name)
+}
+
+// manifestFile is a file written in the component directory, holding one or more documents
+type manifestFile struct {
+	name string
+	docs []helmw.YamlDoc
+}
+
+type resource struct {
+	id  resourceId
+	doc helmw.YamlDoc
+	// nsSuffix is appended to the file name for resources outside the component namespace
+	nsSuffix string
+}
+
+// manifestFiles assigns the documents to files according to the layout. File names are qualified
+// with the API group when two resources of different groups would otherwise share the same file.
+// Documents rendering the same resource more than once are rejected.
+func manifestFiles(docs []helmw.YamlDoc, namespace string, layout string) ([]manifestFile, error) {
+	var resources []resource
+	seen := make(map[resourceId]int)
+	for i, doc := range docs {
+		if len(doc) == 0 {
+			continue
+		}
+
+		r, err := newResource(doc, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource #%d: %w", i+1, err)
+		}
+
+		if j, ok := seen[r.id]; ok {
+			return nil, fmt.Errorf("resource %s is rendered more than once, by documents #%d and #%d", r.id, j+1, i+1)
+		}
+		seen[r.id] = i
+		resources = append(resources, r)
+	}
+
+	if layout == config.LayoutSingleFile {
+		files := []manifestFile{{name: singleFileName}}
+		for _, r := range resources {
+			files[0].docs = append(files[0].docs, r.doc)
+		}
+		return files, nil
+	}
+
+	// qualify the kind with the group only for the resources that would collide
+	byName := make(map[string][]resourceId)
+	for _, r := range resources {
+		n := fileName(r, false, layout)
+		byName[n] = append(byName[n], r.id)
+	}
+
+	files := make([]manifestFile, 0, len(resources))
+	owners := make(map[string]resourceId)
+	for _, r := range resources {
+		n := fileName(r, false, layout)
+		if len(byName[n]) > 1 {
+			n = fileName(r, true, layout)
+		}
+
+		if o, ok := owners[n]; ok {
+			return nil, fmt.Errorf("resources %s and %s would be written to the same file %s", o, r.id, n)
+		}
+		owners[n] = r.id
+		files = append(files, manifestFile{name: n, docs: []helmw.YamlDoc{r.doc}})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].name < files[j].name
+	})
+	return files, nil
+}
+
+func newResource(doc helmw.YamlDoc, namespace string) (resource, error) {
+	var r resource
+	kind, _ := doc["kind"].(string)
+	if kind == "" {
+		return r, fmt.Errorf("missing kind")
+	}
+
+	meta, ok := doc["metadata"].(helmw.YamlDoc)
+	if !ok {
+		m, ok := doc["metadata"].(map[string]any)
+		if !ok {
+			return r, fmt.Errorf("%s is missing metadata", kind)
+		}
+		meta = m
+	}
+
+	name, _ := meta["name"].(string)
+	if name == "" {
+		return r, fmt.Errorf("%s is missing metadata.name", kind)
+	}
+
+	apiVersion, _ := doc["apiVersion"].(string)
+	group := ""
+	if i := strings.LastIndex(apiVersion, "/"); i >= 0 {
+		group = apiVersion[:i]
+	}
+
+	r.doc = doc
+	r.id = resourceId{group: group, kind: kind, name: name}
+	if postrender.IsClusterScoped(kind) {
+		return r, nil
+	}
+
+	ns, _ := meta["namespace"].(string)
+	if ns != "" && ns != namespace {
+		r.nsSuffix = "-" + ns
+	}
+	if ns == "" {
+		ns = namespace
+	}
+	r.id.namespace = ns
+	return r, nil
+}
+
+func fileName(r resource, qualified bool, layout string) string {
+	kind := strings.ToLower(r.id.kind)
+	if qualified {
+		group := r.id.group
+		if group == "" {
+			group = "core"
+		}
+		kind += "." + group
+	}
+
+	if layout == config.LayoutByKind {
+		return path.Join(kind, r.id.name+r.nsSuffix+".yml")
+	}
+	return fmt.Sprintf("%s-%s%s.yml", kind, r.id.name, r.nsSuffix)
+}
@@ -0,0 +1,155 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/karavel-io/cli/internal/helmw"
+	"github.com/karavel-io/cli/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func decodeDocs(t *testing.T, s string) []helmw.YamlDoc {
+	var docs []helmw.YamlDoc
+	dec := yaml.NewDecoder(strings.NewReader(s))
+	for {
+		var doc helmw.YamlDoc
+		if err := dec.Decode(&doc); err != nil {
+			break
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+func fileNames(files []manifestFile) []string {
+	var names []string
+	for _, f := range files {
+		names = append(names, f.name)
+	}
+	return names
+}
+
+const layoutManifests = `
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: tls
+---
+apiVersion: acme.example.com/v1
+kind: Certificate
+metadata:
+  name: tls
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: monitoring
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: dex
+  namespace: dex
+`
+
+func TestManifestFiles(t *testing.T) {
+	docs := decodeDocs(t, layoutManifests)
+
+	files, err := manifestFiles(docs, "dex", config.LayoutFlat)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"certificate.acme.example.com-tls.yml",
+		"certificate.cert-manager.io-tls.yml",
+		"clusterrole-dex.yml",
+		"configmap-config-monitoring.yml",
+	}, fileNames(files))
+
+	files, err = manifestFiles(docs, "dex", config.LayoutByKind)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"certificate.acme.example.com/tls.yml",
+		"certificate.cert-manager.io/tls.yml",
+		"clusterrole/dex.yml",
+		"configmap/config-monitoring.yml",
+	}, fileNames(files))
+
+	files, err = manifestFiles(docs, "dex", config.LayoutSingleFile)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, singleFileName, files[0].name)
+	assert.Len(t, files[0].docs, 4)
+}
+
+func TestManifestFilesErrors(t *testing.T) {
+	_, err := manifestFiles(decodeDocs(t, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: dex
+`), "dex", config.LayoutFlat)
+	assert.ErrorContains(t, err, "resource ConfigMap dex/config is rendered more than once, by documents #1 and #2")
+
+	_, err = manifestFiles(decodeDocs(t, `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: dex
+  namespace: a
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: dex
+  namespace: b
+`), "dex", config.LayoutSingleFile)
+	assert.ErrorContains(t, err, "resource ClusterRole.rbac.authorization.k8s.io dex is rendered more than once")
+
+	_, err = manifestFiles(decodeDocs(t, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a-b
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  namespace: b
+`), "dex", config.LayoutFlat)
+	assert.ErrorContains(t, err, "resources ConfigMap dex/a-b and ConfigMap b/a would be written to the same file configmap.core-a-b.yml")
+
+	_, err = manifestFiles(decodeDocs(t, `
+apiVersion: batch/v1
+kind: Job
+spec: {}
+`), "dex", config.LayoutFlat)
+	assert.ErrorContains(t, err, "invalid resource #1: Job is missing metadata")
+
+	_, err = manifestFiles([]helmw.YamlDoc{{"metadata": helmw.YamlDoc{"name": "x"}}}, "dex", config.LayoutFlat)
+	assert.ErrorContains(t, err, "missing kind")
+}
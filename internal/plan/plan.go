@@ -17,9 +17,12 @@ package plan
 import (
 	"context"
 	"fmt"
-	"sync"
+	"sort"
 
 	"github.com/karavel-io/cli/internal/helmw"
+	"github.com/karavel-io/cli/internal/postrender"
+	"github.com/karavel-io/cli/internal/secrets"
+	"github.com/karavel-io/cli/internal/utils"
 	"github.com/karavel-io/cli/pkg/config"
 	"github.com/karavel-io/cli/pkg/logger"
 )
@@ -30,66 +33,114 @@ type Plan struct {
 	log            logger.Logger
 }
 
-func NewFromConfig(ctx context.Context, cfg *config.Config) (*Plan, error) {
+// NewFromConfig loads the components of the config, fetching at most parallelism of them at the same time.
+// A parallelism of zero or less defaults to the number of CPUs.
+func NewFromConfig(ctx context.Context, cfg *config.Config, parallelism int) (*Plan, error) {
 	log := logger.FromContext(ctx)
 	p := New(log)
 
-	var wg sync.WaitGroup
-	ch := make(chan error)
-	components := make(chan Component, 10)
-	done := make(chan bool)
-	for _, c := range cfg.Components {
-		wg.Add(1)
-		go func(cc config.Component) {
-			defer wg.Done()
-
-			chartName := cc.Name
-			if cc.ComponentName != "" {
-				chartName = cc.ComponentName
-			}
+	sealer, external, err := newSecretsBackends(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-			log.Debugf("Loading component '%s'", chartName)
-			meta, err := helmw.GetChartManifest(ctx, chartName, cc.Version, cc.Unstable)
-			if err != nil {
-				ch <- fmt.Errorf("failed to load component '%s': %w", chartName, err)
-				return
-			}
-			comp, err := NewComponentFromChartMetadata(meta, cc.Unstable)
-			if err != nil {
-				ch <- fmt.Errorf("failed to instantiate component configuration: %w", err)
-				return
-			}
-			if cc.ComponentName != "" {
-				comp.name = cc.Name
+	components := make([]Component, len(cfg.Components))
+	err = utils.ForEach(ctx, parallelism, cfg.Components, func(ctx context.Context, i int, cc config.Component) error {
+		chartName := cc.Name
+		if cc.ComponentName != "" {
+			chartName = cc.ComponentName
+		}
+
+		log.Debugf("Loading component '%s'", chartName)
+		ch, err := helmw.LoadChart(ctx, chartName, cc.Version, cc.Unstable)
+		if err != nil {
+			return fmt.Errorf("failed to load component '%s': %w", chartName, err)
+		}
+		comp, err := NewComponentFromChartMetadata(ch.Metadata, cc.Unstable)
+		if err != nil {
+			return fmt.Errorf("failed to instantiate component '%s' configuration: %w", cc.Name, err)
+		}
+		if cc.ComponentName != "" {
+			comp.name = cc.Name
+		}
+
+		comp.namespace = cc.Namespace
+		comp.chartNamespace, _ = ch.Values["namespace"].(string)
+		comp.jsonParams = cc.JsonParams
+		comp.secretParams = cc.SecretParams
+		comp.layout = cc.Layout
+		kopts, err := newKustomizeOptions(cc.Kustomize)
+		if err != nil {
+			return fmt.Errorf("failed to configure component '%s': %w", cc.Name, err)
+		}
+		comp.kustomize = kopts
+
+		prs := append(append([]config.PostRenderer{}, cfg.PostRenderers...), cc.PostRenderers...)
+		comp.postRenderers, err = newPostRenderers(prs, cc.Namespace)
+		if err != nil {
+			return fmt.Errorf("failed to configure component '%s': %w", cc.Name, err)
+		}
+
+		if len(cfg.RegistryMirrors) > 0 {
+			comp.mirrors = &postrender.Registry{Rewrites: cfg.RegistryMirrors}
+		}
+
+		if cc.Secrets != nil {
+			comp.secrets = &secrets.Options{
+				Mode:     secrets.Mode(cc.Secrets.Mode),
+				Names:    cc.Secrets.Names,
+				Sealer:   sealer,
+				External: external,
 			}
+		}
 
-			comp.namespace = cc.Namespace
-			comp.jsonParams = cc.JsonParams
+		components[i] = comp
 
-			components <- comp
+		log.Debugf("Loaded component %s", comp.DebugLabel())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-			log.Debugf("Loaded component %s", comp.DebugLabel())
-		}(c)
+	for _, comp := range components {
+		if err := p.AddComponent(comp); err != nil {
+			return nil, fmt.Errorf("failed to build plan from config: %w", err)
+		}
 	}
 
-	go func() {
-		wg.Wait()
-		done <- true
-	}()
+	return &p, nil
+}
+
+// newSecretsBackends sets up the sealed and external secrets backends used by the components.
+// The sealing certificate is only read if a component uses sealed secrets.
+func newSecretsBackends(cfg *config.Config) (*secrets.Sealer, *secrets.ExternalOptions, error) {
+	var sealer *secrets.Sealer
+	var external *secrets.ExternalOptions
+	for _, cc := range cfg.Components {
+		if cc.Secrets == nil {
+			continue
+		}
 
-	for {
-		select {
-		case err := <-ch:
-			return nil, err
-		case comp := <-components:
-			err := p.AddComponent(comp)
+		if cc.Secrets.Mode == config.SecretsModeSealed && sealer == nil && cfg.SealedSecrets != nil {
+			s, err := secrets.NewSealer(cfg.SealedSecrets.Certificate, secrets.Scope(cfg.SealedSecrets.Scope))
 			if err != nil {
-				return nil, fmt.Errorf("failed to build plan from config: %w", err)
+				return nil, nil, err
 			}
-		case <-done:
-			return &p, nil
+			sealer = s
 		}
 	}
+
+	if es := cfg.ExternalSecrets; es != nil {
+		external = &secrets.ExternalOptions{
+			Store:           es.Store,
+			StoreKind:       es.StoreKind,
+			RefreshInterval: es.RefreshInterval,
+			KeyPrefix:       es.KeyPrefix,
+		}
+	}
+
+	return sealer, external, nil
 }
 
 func New(log logger.Logger) Plan {
@@ -109,6 +160,51 @@ func (p *Plan) Components() []*Component {
 	return cc
 }
 
+// SortedComponents returns the components in dependency order, each component following the ones it depends on.
+// Components without a dependency relation are sorted by name, and so are cycles broken.
+func (p *Plan) SortedComponents() []*Component {
+	pending := make(map[string]int, len(p.components))
+	dependents := make(map[string][]string)
+	for n, c := range p.components {
+		for _, d := range c.dependencies {
+			if p.HasComponent(d) {
+				pending[n]++
+				dependents[d] = append(dependents[d], n)
+			}
+		}
+	}
+
+	done := make(map[string]bool, len(p.components))
+	sorted := make([]*Component, 0, len(p.components))
+	for len(sorted) < len(p.components) {
+		var ready []string
+		for n := range p.components {
+			if !done[n] && pending[n] == 0 {
+				ready = append(ready, n)
+			}
+		}
+
+		if len(ready) == 0 {
+			// every remaining component is part of, or depends on, a cycle
+			for n := range p.components {
+				if !done[n] {
+					ready = append(ready, n)
+				}
+			}
+		}
+
+		sort.Strings(ready)
+		n := ready[0]
+		done[n] = true
+		sorted = append(sorted, p.components[n])
+		for _, d := range dependents[n] {
+			pending[d]--
+		}
+	}
+
+	return sorted
+}
+
 func (p *Plan) GetComponent(name string) *Component {
 	return p.components[name]
 }
@@ -131,6 +227,13 @@ func (p *Plan) AddComponent(c Component) error {
 	return nil
 }
 
+// PinDigests makes all the components pin their container images to digests resolved by d
+func (p *Plan) PinDigests(d *postrender.PinDigests) {
+	for _, c := range p.components {
+		c.digests = d
+	}
+}
+
 func (p *Plan) HasComponent(name string) bool {
 	return p.components[name] != nil
 }
@@ -127,3 +127,19 @@ func TestPlan_IntegrationsProcessing(t *testing.T) {
 	assert.True(t, c1p.integrations["cool.feature"])
 	assert.False(t, c2p.integrations["cool.feature"])
 }
+
+func TestPlan_SortedComponents(t *testing.T) {
+	p := New(log)
+	assert.NoError(t, p.AddComponent(Component{name: "grafana", dependencies: []string{"prometheus"}}))
+	assert.NoError(t, p.AddComponent(Component{name: "prometheus", dependencies: []string{"cert-manager"}}))
+	assert.NoError(t, p.AddComponent(Component{name: "cert-manager"}))
+	assert.NoError(t, p.AddComponent(Component{name: "argocd"}))
+	assert.NoError(t, p.AddComponent(Component{name: "dex", dependencies: []string{"velero"}}))
+	assert.NoError(t, p.AddComponent(Component{name: "velero", dependencies: []string{"dex"}}))
+
+	var names []string
+	for _, c := range p.SortedComponents() {
+		names = append(names, c.Name())
+	}
+	assert.Equal(t, []string{"argocd", "cert-manager", "prometheus", "grafana", "dex", "velero"}, names)
+}
@@ -0,0 +1,48 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"errors"
+
+	"github.com/karavel-io/cli/internal/postrender"
+	"github.com/karavel-io/cli/pkg/config"
+)
+
+// newPostRenderers builds the post-renderers of a component, defaulting the namespace
+// post-renderer to the component namespace
+func newPostRenderers(prs []config.PostRenderer, namespace string) ([]postrender.PostRenderer, error) {
+	var res []postrender.PostRenderer
+	for _, pr := range prs {
+		switch pr.Type {
+		case config.PostRendererExec:
+			res = append(res, &postrender.Exec{Command: pr.Command, Args: pr.Args})
+		case config.PostRendererLabels:
+			res = append(res, &postrender.Labels{Labels: pr.Labels})
+		case config.PostRendererRegistry:
+			res = append(res, &postrender.Registry{Rewrites: pr.Rewrites})
+		case config.PostRendererNamespace:
+			ns := pr.Namespace
+			if ns == "" {
+				ns = namespace
+			}
+			if ns == "" {
+				return nil, errors.New("namespace post-renderer requires either its namespace or the component namespace to be set")
+			}
+			res = append(res, &postrender.Namespace{Namespace: ns})
+		}
+	}
+	return res, nil
+}
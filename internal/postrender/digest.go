@@ -0,0 +1,118 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postrender
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/karavel-io/cli/internal/helmw"
+)
+
+// DigestResolver resolves a container image to its manifest digest
+type DigestResolver interface {
+	Resolve(ctx context.Context, image string) (string, error)
+}
+
+// PinDigests pins the container images found in pod specs to their manifest digest.
+// Resolved digests are cached, so that a PinDigests can be shared by all the components of a render.
+type PinDigests struct {
+	resolver DigestResolver
+	mu       sync.Mutex
+	cache    map[string]string
+	resolved map[string]string
+}
+
+// NewPinDigests creates a PinDigests, seeding its cache with previously resolved digests
+func NewPinDigests(resolver DigestResolver, cache map[string]string) *PinDigests {
+	c := make(map[string]string, len(cache))
+	for k, v := range cache {
+		c[k] = v
+	}
+
+	return &PinDigests{
+		resolver: resolver,
+		cache:    c,
+		resolved: make(map[string]string),
+	}
+}
+
+func (p *PinDigests) Run(ctx context.Context, docs []helmw.YamlDoc) ([]helmw.YamlDoc, error) {
+	var images []string
+	for _, doc := range docs {
+		WalkImages(doc, func(image string) string {
+			if !strings.Contains(image, "@") {
+				images = append(images, image)
+			}
+			return image
+		})
+	}
+
+	digests := make(map[string]string, len(images))
+	for _, image := range images {
+		d, err := p.digest(ctx, image)
+		if err != nil {
+			return nil, err
+		}
+		digests[image] = d
+	}
+
+	for _, doc := range docs {
+		WalkImages(doc, func(image string) string {
+			if d, ok := digests[image]; ok {
+				return image + "@" + d
+			}
+			return image
+		})
+	}
+	return docs, nil
+}
+
+func (p *PinDigests) digest(ctx context.Context, image string) (string, error) {
+	p.mu.Lock()
+	d, ok := p.cache[image]
+	p.mu.Unlock()
+
+	if !ok {
+		var err error
+		d, err = p.resolver.Resolve(ctx, image)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cache[image] = d
+	p.resolved[image] = d
+	return d, nil
+}
+
+// Resolved returns the digests of the images pinned so far
+func (p *PinDigests) Resolved() map[string]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	res := make(map[string]string, len(p.resolved))
+	for k, v := range p.resolved {
+		res[k] = v
+	}
+	return res
+}
+
+func (p *PinDigests) String() string {
+	return "pin digests"
+}
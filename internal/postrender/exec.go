@@ -0,0 +1,89 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postrender
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/karavel-io/cli/internal/helmw"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Exec pipes the documents through an external command, following the Helm post-renderer protocol:
+// the rendered manifests are written to its stdin and the modified ones are read from its stdout.
+type Exec struct {
+	Command string
+	Args    []string
+}
+
+func (e *Exec) Run(ctx context.Context, docs []helmw.YamlDoc) ([]helmw.YamlDoc, error) {
+	var in bytes.Buffer
+	enc := yaml.NewEncoder(&in)
+	enc.SetIndent(2)
+	for _, d := range docs {
+		if len(d) == 0 {
+			continue
+		}
+		if err := enc.Encode(d); err != nil {
+			return nil, fmt.Errorf("failed to encode documents: %w", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode documents: %w", err)
+	}
+
+	var out, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.Command, e.Args...)
+	cmd.Stdin = &in
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// the command was killed because the render was stopped
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+
+	var res []helmw.YamlDoc
+	dec := yaml.NewDecoder(&out)
+	for {
+		var doc helmw.YamlDoc
+		if err := dec.Decode(&doc); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode command output: %w", err)
+		}
+		if len(doc) > 0 {
+			res = append(res, doc)
+		}
+	}
+
+	return res, nil
+}
+
+func (e *Exec) String() string {
+	return fmt.Sprintf("exec '%s'", e.Command)
+}
@@ -0,0 +1,50 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postrender
+
+import (
+	"context"
+
+	"github.com/karavel-io/cli/internal/helmw"
+)
+
+// Labels adds the given labels to every document, overriding existing values
+type Labels struct {
+	Labels map[string]string
+}
+
+func (l *Labels) Run(_ context.Context, docs []helmw.YamlDoc) ([]helmw.YamlDoc, error) {
+	for _, doc := range docs {
+		if len(doc) == 0 {
+			continue
+		}
+
+		meta := metadata(doc)
+		labels, ok := asMap(meta["labels"])
+		if !ok {
+			l := helmw.YamlDoc{}
+			meta["labels"] = l
+			labels = l
+		}
+		for k, v := range l.Labels {
+			labels[k] = v
+		}
+	}
+	return docs, nil
+}
+
+func (l *Labels) String() string {
+	return "labels"
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postrender
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/karavel-io/cli/internal/helmw"
+)
+
+// clusterScopedKinds lists the well-known kinds that must not be assigned a namespace
+var clusterScopedKinds = map[string]bool{
+	"APIService":                     true,
+	"CertificateSigningRequest":      true,
+	"ClusterIssuer":                  true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"ClusterSecretStore":             true,
+	"CSIDriver":                      true,
+	"CSINode":                        true,
+	"CustomResourceDefinition":       true,
+	"IngressClass":                   true,
+	"MutatingWebhookConfiguration":   true,
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"PodSecurityPolicy":              true,
+	"PriorityClass":                  true,
+	"RuntimeClass":                   true,
+	"StorageClass":                   true,
+	"ValidatingWebhookConfiguration": true,
+	"VolumeAttachment":               true,
+}
+
+// IsClusterScoped reports whether kind is a well-known cluster-scoped kind
+func IsClusterScoped(kind string) bool {
+	return clusterScopedKinds[kind]
+}
+
+// Namespace moves every namespaced document to the given namespace
+type Namespace struct {
+	Namespace string
+	// Default only assigns the namespace to the documents that do not declare one
+	Default bool
+}
+
+func (n *Namespace) Run(_ context.Context, docs []helmw.YamlDoc) ([]helmw.YamlDoc, error) {
+	for _, doc := range docs {
+		if len(doc) == 0 {
+			continue
+		}
+
+		kind, _ := doc["kind"].(string)
+		if IsClusterScoped(kind) {
+			continue
+		}
+		md := metadata(doc)
+		if ns, _ := md["namespace"].(string); n.Default && ns != "" {
+			continue
+		}
+		md["namespace"] = n.Namespace
+	}
+	return docs, nil
+}
+
+func (n *Namespace) String() string {
+	if n.Default {
+		return fmt.Sprintf("default namespace '%s'", n.Namespace)
+	}
+	return fmt.Sprintf("namespace '%s'", n.Namespace)
+}
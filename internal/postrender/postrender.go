@@ -0,0 +1,65 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package postrender implements the post-renderers that modify the documents
+// rendered by a component chart before they are written to disk.
+package postrender
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/karavel-io/cli/internal/helmw"
+)
+
+// PostRenderer transforms the rendered documents of a component
+type PostRenderer interface {
+	Run(ctx context.Context, docs []helmw.YamlDoc) ([]helmw.YamlDoc, error)
+	String() string
+}
+
+// RunAll runs the post-renderers in order, each receiving the output of the previous one
+func RunAll(ctx context.Context, prs []PostRenderer, docs []helmw.YamlDoc) ([]helmw.YamlDoc, error) {
+	for _, pr := range prs {
+		out, err := pr.Run(ctx, docs)
+		if err != nil {
+			return nil, fmt.Errorf("post-renderer %s failed: %w", pr, err)
+		}
+		docs = out
+	}
+	return docs, nil
+}
+
+// asMap returns v as a plain map, as decoded documents nest either YamlDoc or map values
+func asMap(v any) (map[string]any, bool) {
+	switch m := v.(type) {
+	case helmw.YamlDoc:
+		return m, true
+	case map[string]any:
+		return m, true
+	default:
+		return nil, false
+	}
+}
+
+// metadata returns the document metadata, creating it if missing
+func metadata(doc helmw.YamlDoc) map[string]any {
+	if m, ok := asMap(doc["metadata"]); ok {
+		return m
+	}
+
+	m := helmw.YamlDoc{}
+	doc["metadata"] = m
+	return m
+}
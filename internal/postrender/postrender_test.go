@@ -0,0 +1,155 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postrender
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/karavel-io/cli/internal/helmw"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const manifests = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dex
+  namespace: default
+  labels:
+    app: dex
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: init
+          image: busybox
+      containers:
+        - name: dex
+          image: ghcr.io/dexidp/dex:v2.31.0
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: dex
+`
+
+func decode(t *testing.T, s string) []helmw.YamlDoc {
+	var docs []helmw.YamlDoc
+	dec := yaml.NewDecoder(strings.NewReader(s))
+	for {
+		var doc helmw.YamlDoc
+		if err := dec.Decode(&doc); err != nil {
+			break
+		}
+		docs = append(docs, doc)
+	}
+	require.Len(t, docs, 2)
+	return docs
+}
+
+func TestLabels(t *testing.T) {
+	docs, err := RunAll(context.Background(), []PostRenderer{&Labels{Labels: map[string]string{"team": "platform", "app": "idp"}}}, decode(t, manifests))
+	require.NoError(t, err)
+
+	assert.Equal(t, helmw.YamlDoc{"app": "idp", "team": "platform"}, docs[0]["metadata"].(helmw.YamlDoc)["labels"])
+	assert.Equal(t, helmw.YamlDoc{"app": "idp", "team": "platform"}, docs[1]["metadata"].(helmw.YamlDoc)["labels"])
+}
+
+func TestNamespace(t *testing.T) {
+	docs, err := (&Namespace{Namespace: "dex"}).Run(context.Background(), decode(t, manifests))
+	require.NoError(t, err)
+
+	assert.Equal(t, "dex", docs[0]["metadata"].(helmw.YamlDoc)["namespace"])
+	assert.NotContains(t, docs[1]["metadata"], "namespace")
+}
+
+func TestDefaultNamespace(t *testing.T) {
+	docs := decode(t, manifests)
+	delete(docs[0]["metadata"].(helmw.YamlDoc), "namespace")
+	docs = append(docs, helmw.YamlDoc{"kind": "Service", "metadata": helmw.YamlDoc{"name": "dex", "namespace": "kube-system"}})
+
+	docs, err := (&Namespace{Namespace: "dex", Default: true}).Run(context.Background(), docs)
+	require.NoError(t, err)
+
+	assert.Equal(t, "dex", docs[0]["metadata"].(helmw.YamlDoc)["namespace"])
+	assert.NotContains(t, docs[1]["metadata"], "namespace")
+	assert.Equal(t, "kube-system", docs[2]["metadata"].(helmw.YamlDoc)["namespace"])
+}
+
+func TestRegistry(t *testing.T) {
+	r := &Registry{Rewrites: map[string]string{
+		"docker.io": "mirror.local/dockerhub/",
+		"ghcr.io":   "mirror.local/ghcr",
+	}}
+
+	var images []string
+	docs, err := r.Run(context.Background(), decode(t, manifests))
+	require.NoError(t, err)
+	WalkImages(docs[0], func(image string) string {
+		images = append(images, image)
+		return image
+	})
+
+	assert.ElementsMatch(t, []string{"mirror.local/dockerhub/library/busybox", "mirror.local/ghcr/dexidp/dex:v2.31.0"}, images)
+	assert.Equal(t, "quay.io/karavel/cli:main", r.Rewrite("quay.io/karavel/cli:main"))
+}
+
+func TestExec(t *testing.T) {
+	docs, err := (&Exec{Command: "sed", Args: []string{"s/name: dex$/name: idp/"}}).Run(context.Background(), decode(t, manifests))
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "idp", docs[0]["metadata"].(helmw.YamlDoc)["name"])
+	assert.Equal(t, "idp", docs[1]["metadata"].(helmw.YamlDoc)["name"])
+
+	_, err = RunAll(context.Background(), []PostRenderer{&Exec{Command: "sh", Args: []string{"-c", "echo boom >&2; exit 1"}}}, docs)
+	assert.ErrorContains(t, err, "post-renderer exec 'sh' failed")
+	assert.ErrorContains(t, err, "boom")
+}
+
+type fakeResolver map[string]string
+
+func (f fakeResolver) Resolve(_ context.Context, image string) (string, error) {
+	if d, ok := f[image]; ok {
+		return d, nil
+	}
+	return "", fmt.Errorf("image %s not found", image)
+}
+
+func TestPinDigests(t *testing.T) {
+	p := NewPinDigests(fakeResolver{"ghcr.io/dexidp/dex:v2.31.0": "sha256:dex"}, map[string]string{"busybox": "sha256:cached"})
+	docs, err := p.Run(context.Background(), decode(t, manifests))
+	require.NoError(t, err)
+
+	var images []string
+	WalkImages(docs[0], func(image string) string {
+		images = append(images, image)
+		return image
+	})
+	assert.ElementsMatch(t, []string{"busybox@sha256:cached", "ghcr.io/dexidp/dex:v2.31.0@sha256:dex"}, images)
+	assert.Equal(t, map[string]string{"busybox": "sha256:cached", "ghcr.io/dexidp/dex:v2.31.0": "sha256:dex"}, p.Resolved())
+
+	// already pinned images are left untouched
+	_, err = NewPinDigests(fakeResolver{}, nil).Run(context.Background(), docs)
+	require.NoError(t, err)
+
+	_, err = NewPinDigests(fakeResolver{}, nil).Run(context.Background(), decode(t, manifests))
+	assert.ErrorContains(t, err, "not found")
+}
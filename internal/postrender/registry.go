@@ -0,0 +1,89 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postrender
+
+import (
+	"context"
+	"strings"
+
+	"github.com/karavel-io/cli/internal/helmw"
+	"github.com/karavel-io/cli/internal/oci"
+)
+
+// containerKeys are the pod spec fields holding container lists
+var containerKeys = []string{"containers", "initContainers", "ephemeralContainers"}
+
+// Registry rewrites the registry of the container images found in pod specs.
+// Rewrites maps registry hosts to their replacement, which may include a path prefix.
+type Registry struct {
+	Rewrites map[string]string
+}
+
+func (r *Registry) Run(_ context.Context, docs []helmw.YamlDoc) ([]helmw.YamlDoc, error) {
+	for _, doc := range docs {
+		WalkImages(doc, func(image string) string {
+			return r.Rewrite(image)
+		})
+	}
+	return docs, nil
+}
+
+// Rewrite returns the image with its registry replaced, or the image unchanged if no rewrite matches
+func (r *Registry) Rewrite(image string) string {
+	registry, path := oci.SplitImage(image)
+	to, ok := r.Rewrites[registry]
+	if !ok {
+		return image
+	}
+	return strings.TrimSuffix(to, "/") + "/" + path
+}
+
+func (r *Registry) String() string {
+	return "registry"
+}
+
+// WalkImages calls fn on every container image found in v, replacing it with the returned value
+func WalkImages(v any, fn func(image string) string) {
+	switch t := v.(type) {
+	case helmw.YamlDoc:
+		walkImagesMap(t, fn)
+	case map[string]any:
+		walkImagesMap(t, fn)
+	case []any:
+		for _, i := range t {
+			WalkImages(i, fn)
+		}
+	}
+}
+
+func walkImagesMap(m map[string]any, fn func(image string) string) {
+	for _, k := range containerKeys {
+		cc, ok := m[k].([]any)
+		if !ok {
+			continue
+		}
+		for _, c := range cc {
+			if cm, ok := asMap(c); ok {
+				if img, ok := cm["image"].(string); ok && img != "" {
+					cm["image"] = fn(img)
+				}
+			}
+		}
+	}
+
+	for _, v := range m {
+		WalkImages(v, fn)
+	}
+}
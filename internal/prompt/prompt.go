@@ -0,0 +1,135 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var ErrNoInput = errors.New("no input available")
+
+// Prompter asks questions on an interactive terminal
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func New(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{
+		in:  bufio.NewReader(in),
+		out: out,
+	}
+}
+
+// Confirm asks a yes/no question, returning def if the answer is empty
+func (p *Prompter) Confirm(question string, def bool) (bool, error) {
+	choices := "y/N"
+	if def {
+		choices = "Y/n"
+	}
+
+	for {
+		ans, err := p.ask(fmt.Sprintf("%s [%s]: ", question, choices))
+		if err != nil {
+			return false, err
+		}
+
+		switch strings.ToLower(ans) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// Input asks for a free-form answer, returning def if the answer is empty
+func (p *Prompter) Input(question string, def string) (string, error) {
+	q := question + ": "
+	if def != "" {
+		q = fmt.Sprintf("%s [%s]: ", question, def)
+	}
+
+	ans, err := p.ask(q)
+	if err != nil {
+		return "", err
+	}
+
+	if ans == "" {
+		return def, nil
+	}
+	return ans, nil
+}
+
+// Select asks to pick one of the given options, returning its index.
+// The answer can either be the option number or its text, def is returned if the answer is empty.
+func (p *Prompter) Select(question string, options []string, def int) (int, error) {
+	if len(options) == 0 {
+		return -1, errors.New("no options to select from")
+	}
+
+	if _, err := fmt.Fprintln(p.out, question); err != nil {
+		return -1, err
+	}
+	for i, o := range options {
+		if _, err := fmt.Fprintf(p.out, "  %d) %s\n", i+1, o); err != nil {
+			return -1, err
+		}
+	}
+
+	for {
+		ans, err := p.ask(fmt.Sprintf("Choice [%d]: ", def+1))
+		if err != nil {
+			return -1, err
+		}
+
+		if ans == "" {
+			return def, nil
+		}
+
+		if n, err := strconv.Atoi(ans); err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+
+		for i, o := range options {
+			if strings.EqualFold(ans, o) {
+				return i, nil
+			}
+		}
+	}
+}
+
+func (p *Prompter) ask(question string) (string, error) {
+	if _, err := fmt.Fprint(p.out, question); err != nil {
+		return "", err
+	}
+
+	line, err := p.in.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return "", ErrNoInput
+	}
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	return strings.TrimSpace(line), nil
+}
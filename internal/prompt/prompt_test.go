@@ -0,0 +1,71 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prompt
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirm(t *testing.T) {
+	p := New(strings.NewReader("maybe\nyes\n\nN\n"), ioutil.Discard)
+
+	ok, err := p.Confirm("Continue?", false)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = p.Confirm("Continue?", true)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = p.Confirm("Continue?", true)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = p.Confirm("Continue?", true)
+	assert.ErrorIs(t, err, ErrNoInput)
+}
+
+func TestInput(t *testing.T) {
+	p := New(strings.NewReader("example.com\n\n"), ioutil.Discard)
+
+	ans, err := p.Input("Domain", "karavel.local")
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com", ans)
+
+	ans, err = p.Input("Domain", "karavel.local")
+	assert.NoError(t, err)
+	assert.Equal(t, "karavel.local", ans)
+}
+
+func TestSelect(t *testing.T) {
+	p := New(strings.NewReader("4\n2\n\nFull\n"), ioutil.Discard)
+	options := []string{"minimal", "observability", "full"}
+
+	i, err := p.Select("Preset", options, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, i)
+
+	i, err = p.Select("Preset", options, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, i)
+
+	i, err = p.Select("Preset", options, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, i)
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	GitHubDefaultUrl = "https://api.github.com"
+	GitLabDefaultUrl = "https://gitlab.com"
+)
+
+// GitHub reads releases from the tags of a GitHub repository
+type GitHub struct {
+	// ApiUrl defaults to the public GitHub API
+	ApiUrl string
+	Repo   string
+	Token  string
+}
+
+func (g *GitHub) Releases(ctx context.Context) ([]string, error) {
+	api := g.ApiUrl
+	if api == "" {
+		api = GitHubDefaultUrl
+	}
+
+	header := http.Header{}
+	header.Set("Accept", "application/vnd.github+json")
+	if g.Token != "" {
+		header.Set("Authorization", "Bearer "+g.Token)
+	}
+
+	tags, err := fetchTags(ctx, "GitHub", fmt.Sprintf("%s/repos/%s/tags?per_page=100", strings.TrimSuffix(api, "/"), g.Repo), header)
+	if err != nil {
+		return nil, err
+	}
+	return Sort(tags), nil
+}
+
+func (g *GitHub) String() string {
+	return "GitHub repository " + g.Repo
+}
+
+// GitLab reads releases from the tags of a GitLab project
+type GitLab struct {
+	BaseUrl string
+	Project string
+	Token   string
+}
+
+func (g *GitLab) Releases(ctx context.Context) ([]string, error) {
+	header := http.Header{}
+	if g.Token != "" {
+		header.Set("PRIVATE-TOKEN", g.Token)
+	}
+
+	apiUrl := fmt.Sprintf("%s/api/v4/projects/%s/repository/tags?per_page=100", strings.TrimSuffix(g.BaseUrl, "/"), url.PathEscape(g.Project))
+	tags, err := fetchTags(ctx, "GitLab", apiUrl, header)
+	if err != nil {
+		return nil, err
+	}
+	return Sort(tags), nil
+}
+
+func (g *GitLab) String() string {
+	return fmt.Sprintf("GitLab project %s/%s", strings.TrimSuffix(g.BaseUrl, "/"), g.Project)
+}
+
+// Gitea reads releases from the tags of a Gitea repository
+type Gitea struct {
+	BaseUrl string
+	Repo    string
+	Token   string
+}
+
+func (g *Gitea) Releases(ctx context.Context) ([]string, error) {
+	header := http.Header{}
+	if g.Token != "" {
+		header.Set("Authorization", "token "+g.Token)
+	}
+
+	apiUrl := fmt.Sprintf("%s/api/v1/repos/%s/tags?limit=50", strings.TrimSuffix(g.BaseUrl, "/"), g.Repo)
+	tags, err := fetchTags(ctx, "Gitea", apiUrl, header)
+	if err != nil {
+		return nil, err
+	}
+	return Sort(tags), nil
+}
+
+func (g *Gitea) String() string {
+	return fmt.Sprintf("Gitea repository %s/%s", strings.TrimSuffix(g.BaseUrl, "/"), g.Repo)
+}
@@ -0,0 +1,138 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/karavel-io/cli/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTagsServer serves the given tag pages at path, linking each page to the next one
+func newTagsServer(t *testing.T, path string, check func(r *http.Request), pages ...[]string) *httptest.Server {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"message": "Not Found"}`)
+			return
+		}
+		check(r)
+
+		page := 1
+		if p := r.URL.Query().Get("page"); p != "" {
+			page, _ = strconv.Atoi(p)
+		}
+
+		var tags []string
+		if page <= len(pages) {
+			tags = pages[page-1]
+		}
+		if page < len(pages) {
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=%d>; rel="next", <%s%s?page=%d>; rel="last"`, srv.URL, path, page+1, srv.URL, path, len(pages)))
+		}
+
+		_, _ = fmt.Fprint(w, "[")
+		for i, tag := range tags {
+			if i > 0 {
+				_, _ = fmt.Fprint(w, ",")
+			}
+			_, _ = fmt.Fprintf(w, `{"name": "%s"}`, tag)
+		}
+		_, _ = fmt.Fprint(w, "]")
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGitHub(t *testing.T) {
+	srv := newTagsServer(t, "/repos/acme/platform/tags", func(r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+	}, []string{"2022.1", "2022.2-rc.1"}, []string{"2022.2", "docs"})
+
+	src := &GitHub{ApiUrl: srv.URL, Repo: "acme/platform", Token: "secret"}
+	versions, err := src.Releases(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2022.2", "2022.2-rc.1", "2022.1"}, versions)
+
+	latest, err := Latest(context.Background(), src)
+	require.NoError(t, err)
+	assert.Equal(t, "2022.2", latest)
+
+	_, err = (&GitHub{ApiUrl: srv.URL, Repo: "acme/missing"}).Releases(context.Background())
+	assert.ErrorContains(t, err, "Not Found")
+}
+
+func TestGitHubNoReleases(t *testing.T) {
+	srv := newTagsServer(t, "/repos/acme/platform/tags", func(r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+	})
+
+	_, err := Latest(context.Background(), &GitHub{ApiUrl: srv.URL, Repo: "acme/platform"})
+	assert.ErrorIs(t, err, ErrNoReleases)
+}
+
+func TestGitLab(t *testing.T) {
+	srv := newTagsServer(t, "/api/v4/projects/infra/platform/repository/tags", func(r *http.Request) {
+		if r.URL.Query().Get("page") == "" {
+			assert.Contains(t, r.RequestURI, "/projects/infra%2Fplatform/")
+		}
+		assert.Equal(t, "secret", r.Header.Get("PRIVATE-TOKEN"))
+	}, []string{"v2022.1"}, []string{"v2022.2"})
+
+	versions, err := (&GitLab{BaseUrl: srv.URL, Project: "infra/platform", Token: "secret"}).Releases(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2022.2", "2022.1"}, versions)
+}
+
+func TestGitea(t *testing.T) {
+	srv := newTagsServer(t, "/api/v1/repos/infra/platform/tags", func(r *http.Request) {
+		assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+	}, []string{"2022.1", "2021.4"})
+
+	versions, err := (&Gitea{BaseUrl: srv.URL, Repo: "infra/platform", Token: "secret"}).Releases(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2022.1", "2021.4"}, versions)
+}
+
+func TestHelmRepo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `apiVersion: v1
+entries:
+  platform:
+    - name: platform
+      version: 2022.1.0
+    - name: platform
+      version: 2022.2.0
+  other:
+    - name: other
+      version: 3.0.0
+`)
+	}))
+	defer srv.Close()
+
+	ctx := logger.WithLogger(context.Background(), logger.New(logger.LvlError))
+	versions, err := (&HelmRepo{Url: srv.URL, Chart: "platform"}).Releases(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2022.2.0", "2022.1.0"}, versions)
+}
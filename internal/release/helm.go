@@ -0,0 +1,46 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/karavel-io/cli/internal/helmw"
+)
+
+// HelmRepo reads releases from the versions of a chart in a Helm repository,
+// for mirrors that publish one chart version per platform release
+type HelmRepo struct {
+	Url   string
+	Chart string
+}
+
+func (h *HelmRepo) Releases(ctx context.Context) ([]string, error) {
+	idx, err := helmw.LoadIndex(ctx, h.Url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Helm repository %s: %w", h.Url, err)
+	}
+
+	versions := make([]string, 0, len(idx.Entries[h.Chart]))
+	for _, cv := range idx.Entries[h.Chart] {
+		versions = append(versions, cv.Version)
+	}
+	return Sort(versions), nil
+}
+
+func (h *HelmRepo) String() string {
+	return fmt.Sprintf("Helm repository %s (chart %s)", h.Url, h.Chart)
+}
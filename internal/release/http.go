@@ -0,0 +1,101 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+// maxPages caps the number of pages fetched from paginated APIs
+const maxPages = 50
+
+var nextLinkRegexp = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+type apiError struct {
+	Message string `json:"message"`
+	Error   string `json:"error"`
+}
+
+type tag struct {
+	Name string `json:"name"`
+}
+
+// fetchTags collects the tag names from a paginated tags API, following the Link response header
+func fetchTags(ctx context.Context, forge string, apiUrl string, header http.Header) ([]string, error) {
+	var names []string
+	for page := 0; apiUrl != "" && page < maxPages; page++ {
+		req, err := http.NewRequestWithContext(ctx, "GET", apiUrl, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct HTTP request for %s API: %w", forge, err)
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		req.Header.Set("Accept", "application/json")
+
+		tags, next, err := doTagsRequest(req, forge)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range tags {
+			names = append(names, t.Name)
+		}
+
+		if len(tags) == 0 {
+			break
+		}
+		apiUrl = next
+	}
+
+	return names, nil
+}
+
+func doTagsRequest(req *http.Request, forge string) ([]tag, string, error) {
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch releases from %s API: %w", forge, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		var apierr apiError
+		msg := res.Status
+		if err := json.NewDecoder(res.Body).Decode(&apierr); err == nil {
+			if apierr.Message != "" {
+				msg = apierr.Message
+			} else if apierr.Error != "" {
+				msg = apierr.Error
+			}
+		}
+		return nil, "", fmt.Errorf("failed to fetch releases from %s API: %s", forge, msg)
+	}
+
+	tags := make([]tag, 0)
+	if err := json.NewDecoder(res.Body).Decode(&tags); err != nil {
+		return nil, "", fmt.Errorf("failed to decode JSON response from %s API: %w", forge, err)
+	}
+
+	var next string
+	if m := nextLinkRegexp.FindStringSubmatch(res.Header.Get("Link")); m != nil {
+		next = m[1]
+	}
+
+	return tags, next, nil
+}
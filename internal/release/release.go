@@ -0,0 +1,169 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package release lists the Karavel Container Platform releases published by a release source,
+// such as the tags of a GitHub, GitLab or Gitea repository.
+package release
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const DefaultGitHubRepo = "karavel-io/platform"
+
+var ErrNoReleases = errors.New("no releases found")
+
+// Source lists the available platform releases
+type Source interface {
+	// Releases returns the release versions, from the most recent to the oldest
+	Releases(ctx context.Context) ([]string, error)
+	String() string
+}
+
+// Latest returns the most recent release published by the source
+func Latest(ctx context.Context, src Source) (string, error) {
+	versions, err := src.Releases(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if len(versions) == 0 {
+		return "", fmt.Errorf("%w in %s", ErrNoReleases, src)
+	}
+
+	return versions[0], nil
+}
+
+// Parse returns the release source described by spec. Supported formats are:
+//
+//	owner/repo, github:owner/repo           a GitHub repository
+//	github:https://github.example.com/owner/repo
+//	gitlab:group/project                    a GitLab.com project
+//	gitlab:https://gitlab.example.com/group/project
+//	gitea:https://gitea.example.com/owner/repo
+//	helm:https://charts.example.com#chart   the versions of a chart in a Helm repository
+//
+// An empty spec returns the official GitHub repository. API tokens are read from the
+// GITHUB_TOKEN, GITLAB_TOKEN and GITEA_TOKEN environment variables.
+func Parse(spec string) (Source, error) {
+	kind, target := "github", spec
+	if i := strings.Index(spec, ":"); i >= 0 && !strings.HasPrefix(spec[i:], "://") {
+		kind, target = spec[:i], spec[i+1:]
+	}
+
+	switch kind {
+	case "github":
+		if target == "" {
+			target = DefaultGitHubRepo
+		}
+		gh := &GitHub{Repo: target, Token: os.Getenv("GITHUB_TOKEN")}
+		if strings.Contains(target, "://") {
+			base, repo, err := splitRepoUrl(target, "")
+			if err != nil {
+				return nil, err
+			}
+			gh.Repo = repo
+			if base != "https://github.com" {
+				// GitHub Enterprise serves its API under /api/v3
+				gh.ApiUrl = base + "/api/v3"
+			}
+		}
+		return gh, nil
+	case "gitlab":
+		base, project, err := splitRepoUrl(target, GitLabDefaultUrl)
+		if err != nil {
+			return nil, err
+		}
+		return &GitLab{BaseUrl: base, Project: project, Token: os.Getenv("GITLAB_TOKEN")}, nil
+	case "gitea":
+		base, repo, err := splitRepoUrl(target, "")
+		if err != nil {
+			return nil, err
+		}
+		return &Gitea{BaseUrl: base, Repo: repo, Token: os.Getenv("GITEA_TOKEN")}, nil
+	case "helm":
+		u, err := url.Parse(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Helm repository URL %s: %w", target, err)
+		}
+		if u.Fragment == "" {
+			return nil, fmt.Errorf("missing chart name in Helm release source %s, use helm:<repo-url>#<chart>", spec)
+		}
+		chart := u.Fragment
+		u.Fragment = ""
+		return &HelmRepo{Url: u.String(), Chart: chart}, nil
+	default:
+		return nil, fmt.Errorf("unsupported release source type '%s'", kind)
+	}
+}
+
+// splitRepoUrl splits a repository URL into the forge base URL and the repository path.
+// Plain paths are resolved against def, if set.
+func splitRepoUrl(target string, def string) (string, string, error) {
+	if !strings.Contains(target, "://") {
+		if def == "" {
+			return "", "", fmt.Errorf("invalid repository %s, a full URL is required", target)
+		}
+		return def, strings.Trim(target, "/"), nil
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid repository URL %s: %w", target, err)
+	}
+
+	path := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if path == "" {
+		return "", "", fmt.Errorf("invalid repository URL %s, missing repository path", target)
+	}
+
+	return fmt.Sprintf("%s://%s", u.Scheme, u.Host), path, nil
+}
+
+// Sort orders releases from the most recent to the oldest, using semantic versioning precedence.
+// CalVer releases such as 2022.1 are compared as 2022.1.0, so that final releases come after their
+// release candidates. Tags that are not valid versions are discarded, and a leading 'v' is stripped.
+func Sort(tags []string) []string {
+	type release struct {
+		name string
+		ver  *semver.Version
+	}
+
+	releases := make([]release, 0, len(tags))
+	for _, t := range tags {
+		v, err := semver.NewVersion(t)
+		if err != nil {
+			continue
+		}
+		releases = append(releases, release{name: strings.TrimPrefix(t, "v"), ver: v})
+	}
+
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].ver.GreaterThan(releases[j].ver)
+	})
+
+	versions := make([]string, len(releases))
+	for i, r := range releases {
+		versions[i] = r.name
+	}
+	return versions
+}
@@ -0,0 +1,67 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSort(t *testing.T) {
+	rels := []string{"2022.1", "2021.2", "2022.5-rc.1", "2022.5", "2022.5-rc.7", "2022.4-rc.2", "2022.10", "2022.5-rc.10", "v2022.9", "latest"}
+	exp := []string{"2022.10", "2022.9", "2022.5", "2022.5-rc.10", "2022.5-rc.7", "2022.5-rc.1", "2022.4-rc.2", "2022.1", "2021.2"}
+
+	assert.Equal(t, exp, Sort(rels))
+	assert.Empty(t, Sort(nil))
+}
+
+func TestParse(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "gh-token")
+
+	src, err := Parse("")
+	require.NoError(t, err)
+	assert.Equal(t, &GitHub{Repo: DefaultGitHubRepo, Token: "gh-token"}, src)
+
+	src, err = Parse("acme/platform")
+	require.NoError(t, err)
+	assert.Equal(t, &GitHub{Repo: "acme/platform", Token: "gh-token"}, src)
+
+	src, err = Parse("github:https://github.acme.com/infra/platform.git")
+	require.NoError(t, err)
+	assert.Equal(t, &GitHub{ApiUrl: "https://github.acme.com/api/v3", Repo: "infra/platform", Token: "gh-token"}, src)
+
+	src, err = Parse("gitlab:infra/karavel/platform")
+	require.NoError(t, err)
+	assert.Equal(t, &GitLab{BaseUrl: GitLabDefaultUrl, Project: "infra/karavel/platform"}, src)
+
+	src, err = Parse("gitlab:https://gitlab.acme.com/infra/platform")
+	require.NoError(t, err)
+	assert.Equal(t, &GitLab{BaseUrl: "https://gitlab.acme.com", Project: "infra/platform"}, src)
+
+	src, err = Parse("gitea:https://git.acme.com/infra/platform")
+	require.NoError(t, err)
+	assert.Equal(t, &Gitea{BaseUrl: "https://git.acme.com", Repo: "infra/platform"}, src)
+
+	src, err = Parse("helm:https://charts.acme.com/karavel#platform")
+	require.NoError(t, err)
+	assert.Equal(t, &HelmRepo{Url: "https://charts.acme.com/karavel", Chart: "platform"}, src)
+
+	for _, spec := range []string{"gitea:infra/platform", "helm:https://charts.acme.com", "bitbucket:infra/platform"} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
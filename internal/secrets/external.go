@@ -0,0 +1,74 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+// ExternalOptions configures the ExternalSecret references replacing the Secrets
+type ExternalOptions struct {
+	Store     string
+	StoreKind string
+	// RefreshInterval defaults to 1h
+	RefreshInterval string
+	// KeyPrefix is prepended to the remote key of each secret, which is <namespace>/<name>
+	KeyPrefix string
+}
+
+// Reference returns an ExternalSecret pulling the secret keys from the configured store.
+// Each key is read from the property of the same name in the remote secret.
+func (o *ExternalOptions) Reference(sec secret) map[string]any {
+	storeKind := o.StoreKind
+	if storeKind == "" {
+		storeKind = "ClusterSecretStore"
+	}
+	refresh := o.RefreshInterval
+	if refresh == "" {
+		refresh = "1h"
+	}
+
+	remoteKey := o.KeyPrefix + sec.namespace + "/" + sec.name
+	data := make([]any, 0, len(sec.data))
+	for _, k := range sec.keys() {
+		data = append(data, map[string]any{
+			"secretKey": k,
+			"remoteRef": map[string]any{
+				"key":      remoteKey,
+				"property": k,
+			},
+		})
+	}
+
+	tpl := map[string]any{"metadata": sec.templateMetadata()}
+	if sec.secretType != "" {
+		tpl["type"] = sec.secretType
+	}
+
+	return map[string]any{
+		"apiVersion": "external-secrets.io/v1beta1",
+		"kind":       "ExternalSecret",
+		"metadata":   sec.metadata(),
+		"spec": map[string]any{
+			"refreshInterval": refresh,
+			"secretStoreRef": map[string]any{
+				"name": o.Store,
+				"kind": storeKind,
+			},
+			"target": map[string]any{
+				"name":           sec.name,
+				"creationPolicy": "Owner",
+				"template":       tpl,
+			},
+			"data": data,
+		},
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+type Scope string
+
+const (
+	ScopeStrict        Scope = "strict"
+	ScopeNamespaceWide Scope = "namespace-wide"
+	ScopeClusterWide   Scope = "cluster-wide"
+
+	sessionKeyBytes = 32
+)
+
+// Sealer encrypts Secrets into SealedSecrets, using the same hybrid RSA-OAEP and AES-GCM scheme as kubeseal
+type Sealer struct {
+	key   *rsa.PublicKey
+	scope Scope
+	rand  io.Reader
+}
+
+// NewSealer reads the sealed-secrets controller public certificate, as printed by 'kubeseal --fetch-cert'
+func NewSealer(certFile string, scope Scope) (*Sealer, error) {
+	data, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sealed secrets certificate: %w", err)
+	}
+
+	key, err := parsePublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sealed secrets certificate %s: %w", certFile, err)
+	}
+
+	switch scope {
+	case "":
+		scope = ScopeStrict
+	case ScopeStrict, ScopeNamespaceWide, ScopeClusterWide:
+	default:
+		return nil, fmt.Errorf("unsupported sealed secrets scope '%s'", scope)
+	}
+
+	return &Sealer{key: key, scope: scope, rand: rand.Reader}, nil
+}
+
+func parsePublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+
+	var pub any
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		pub = cert.PublicKey
+	case "PUBLIC KEY":
+		k, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		pub = k
+	default:
+		return nil, fmt.Errorf("unexpected PEM block type %s", block.Type)
+	}
+
+	key, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is not an RSA key")
+	}
+	return key, nil
+}
+
+// Fingerprint identifies the certificate and scope used to seal secrets
+func (s *Sealer) Fingerprint() string {
+	sum := sha256.Sum256(x509.MarshalPKCS1PublicKey(s.key))
+	return fmt.Sprintf("%x/%s", sum, s.scope)
+}
+
+// label binds the ciphertext to the secret name and namespace, depending on the scope
+func (s *Sealer) label(sec secret) []byte {
+	switch s.scope {
+	case ScopeClusterWide:
+		return nil
+	case ScopeNamespaceWide:
+		return []byte(sec.namespace)
+	default:
+		return []byte(sec.namespace + "/" + sec.name)
+	}
+}
+
+// Seal encrypts every value of the secret into a SealedSecret
+func (s *Sealer) Seal(sec secret) (map[string]any, error) {
+	if sec.namespace == "" && s.scope != ScopeClusterWide {
+		return nil, fmt.Errorf("secret %s has no namespace, which is required by the %s sealing scope", sec.name, s.scope)
+	}
+
+	label := s.label(sec)
+	encrypted := make(map[string]any, len(sec.data))
+	for _, k := range sec.keys() {
+		ct, err := hybridEncrypt(s.rand, s.key, sec.data[k], label)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seal key %s of secret %s/%s: %w", k, sec.namespace, sec.name, err)
+		}
+		encrypted[k] = base64.StdEncoding.EncodeToString(ct)
+	}
+
+	meta := sec.metadata()
+	switch s.scope {
+	case ScopeNamespaceWide:
+		meta["annotations"] = map[string]any{"sealedsecrets.bitnami.com/namespace-wide": "true"}
+	case ScopeClusterWide:
+		meta["annotations"] = map[string]any{"sealedsecrets.bitnami.com/cluster-wide": "true"}
+	}
+
+	template := sec.templateMetadata()
+	tpl := map[string]any{"metadata": template}
+	if sec.secretType != "" {
+		tpl["type"] = sec.secretType
+	}
+
+	return map[string]any{
+		"apiVersion": "bitnami.com/v1alpha1",
+		"kind":       "SealedSecret",
+		"metadata":   meta,
+		"spec": map[string]any{
+			"encryptedData": encrypted,
+			"template":      tpl,
+		},
+	}, nil
+}
+
+// hybridEncrypt encrypts plaintext with a random AES-256-GCM session key, itself encrypted with RSA-OAEP.
+// The output is the 2 bytes big endian length of the encrypted session key, the encrypted session key and the AES ciphertext.
+func hybridEncrypt(rnd io.Reader, pub *rsa.PublicKey, plaintext []byte, label []byte) ([]byte, error) {
+	sessionKey := make([]byte, sessionKeyBytes)
+	if _, err := io.ReadFull(rnd, sessionKey); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaCiphertext, err := rsa.EncryptOAEP(sha256.New(), rnd, pub, sessionKey, label)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 2, 2+len(rsaCiphertext)+len(plaintext)+gcm.Overhead())
+	binary.BigEndian.PutUint16(out, uint16(len(rsaCiphertext)))
+	out = append(out, rsaCiphertext...)
+
+	// the session key is only used once, so a zero nonce is safe
+	nonce := make([]byte, gcm.NonceSize())
+	return gcm.Seal(out, nonce, plaintext, nil), nil
+}
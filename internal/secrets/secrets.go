@@ -0,0 +1,170 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secrets replaces the plaintext Secrets rendered by components with SealedSecrets
+// or ExternalSecret references, so that no secret data is committed to git
+package secrets
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+type Mode string
+
+const (
+	ModeSealed   Mode = "sealed"
+	ModeExternal Mode = "external"
+)
+
+// Options configures how the Secrets of a component are transformed
+type Options struct {
+	Mode Mode
+	// Names restricts the transformation to the listed Secrets. All Secrets are transformed if empty
+	Names    []string
+	Sealer   *Sealer
+	External *ExternalOptions
+}
+
+// IsSecret reports whether doc is a v1 Secret
+func IsSecret(doc map[string]any) bool {
+	return doc["apiVersion"] == "v1" && doc["kind"] == "Secret"
+}
+
+// Matches reports whether the Secret with the given name should be transformed
+func (o *Options) Matches(name string) bool {
+	if len(o.Names) == 0 {
+		return true
+	}
+
+	for _, n := range o.Names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Transform converts a Secret into a SealedSecret or an ExternalSecret, depending on the configured mode.
+// namespace is used for Secrets that do not declare one.
+func (o *Options) Transform(doc map[string]any, namespace string) (map[string]any, error) {
+	s, err := parseSecret(doc, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	switch o.Mode {
+	case ModeSealed:
+		if o.Sealer == nil {
+			return nil, fmt.Errorf("secret %s/%s: sealed secrets are not configured", s.namespace, s.name)
+		}
+		return o.Sealer.Seal(s)
+	case ModeExternal:
+		if o.External == nil {
+			return nil, fmt.Errorf("secret %s/%s: external secrets are not configured", s.namespace, s.name)
+		}
+		return o.External.Reference(s), nil
+	default:
+		return nil, fmt.Errorf("unsupported secrets mode '%s'", o.Mode)
+	}
+}
+
+type secret struct {
+	name        string
+	namespace   string
+	secretType  string
+	labels      map[string]any
+	annotations map[string]any
+	data        map[string][]byte
+}
+
+func parseSecret(doc map[string]any, namespace string) (secret, error) {
+	s := secret{namespace: namespace, data: make(map[string][]byte)}
+
+	meta := asMap(doc["metadata"])
+	s.name, _ = meta["name"].(string)
+	if s.name == "" {
+		return s, fmt.Errorf("secret is missing metadata.name")
+	}
+	if ns, ok := meta["namespace"].(string); ok && ns != "" {
+		s.namespace = ns
+	}
+	s.labels = asMap(meta["labels"])
+	s.annotations = asMap(meta["annotations"])
+	s.secretType, _ = doc["type"].(string)
+
+	if data := asMap(doc["data"]); data != nil {
+		for k, v := range data {
+			str, _ := v.(string)
+			b, err := base64.StdEncoding.DecodeString(str)
+			if err != nil {
+				return s, fmt.Errorf("secret %s/%s: invalid base64 value for key %s: %w", s.namespace, s.name, k, err)
+			}
+			s.data[k] = b
+		}
+	}
+
+	if data := asMap(doc["stringData"]); data != nil {
+		for k, v := range data {
+			s.data[k] = []byte(fmt.Sprint(v))
+		}
+	}
+
+	return s, nil
+}
+
+func (s secret) keys() []string {
+	keys := make([]string, 0, len(s.data))
+	for k := range s.data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// templateMetadata returns the metadata to apply to the Secret generated in the cluster
+func (s secret) templateMetadata() map[string]any {
+	meta := make(map[string]any)
+	if len(s.labels) > 0 {
+		meta["labels"] = s.labels
+	}
+	if len(s.annotations) > 0 {
+		meta["annotations"] = s.annotations
+	}
+	return meta
+}
+
+func (s secret) metadata() map[string]any {
+	return map[string]any{
+		"name":      s.name,
+		"namespace": s.namespace,
+	}
+}
+
+var mapType = reflect.TypeOf(map[string]any{})
+
+// asMap converts decoded YAML mappings, which may use a named map type, to a plain map
+func asMap(v any) map[string]any {
+	if m, ok := v.(map[string]any); ok {
+		return m
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Map && rv.Type().ConvertibleTo(mapType) {
+		return rv.Convert(mapType).Interface().(map[string]any)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type namedMap map[string]any
+
+func testSecret() map[string]any {
+	return map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		// decoded YAML uses the same named map type for nested mappings
+		"metadata": namedMap{
+			"name":   "dex-oidc",
+			"labels": namedMap{"app": "dex"},
+		},
+		"type": "Opaque",
+		"data": namedMap{
+			"clientSecret": base64.StdEncoding.EncodeToString([]byte("s3cr3t")),
+		},
+		"stringData": namedMap{
+			"clientID": "karavel",
+		},
+	}
+}
+
+func writeCert(t *testing.T, key *rsa.PrivateKey) string {
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "cert.pem")
+	require.NoError(t, ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644))
+	return path
+}
+
+// hybridDecrypt mirrors the sealed-secrets controller decryption
+func hybridDecrypt(t *testing.T, key *rsa.PrivateKey, ciphertext []byte, label []byte) string {
+	l := int(binary.BigEndian.Uint16(ciphertext))
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, ciphertext[2:2+l], label)
+	require.NoError(t, err)
+
+	block, err := aes.NewCipher(sessionKey)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+
+	plaintext, err := gcm.Open(nil, make([]byte, gcm.NonceSize()), ciphertext[2+l:], nil)
+	require.NoError(t, err)
+	return string(plaintext)
+}
+
+func TestSeal(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	sealer, err := NewSealer(writeCert(t, key), "")
+	require.NoError(t, err)
+
+	opts := &Options{Mode: ModeSealed, Sealer: sealer}
+	res, err := opts.Transform(testSecret(), "dex")
+	require.NoError(t, err)
+
+	assert.Equal(t, "SealedSecret", res["kind"])
+	assert.Equal(t, map[string]any{"name": "dex-oidc", "namespace": "dex"}, res["metadata"])
+
+	spec := res["spec"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"type":     "Opaque",
+		"metadata": map[string]any{"labels": map[string]any{"app": "dex"}},
+	}, spec["template"])
+
+	encrypted := spec["encryptedData"].(map[string]any)
+	require.Len(t, encrypted, 2)
+	for k, exp := range map[string]string{"clientSecret": "s3cr3t", "clientID": "karavel"} {
+		ct, err := base64.StdEncoding.DecodeString(encrypted[k].(string))
+		require.NoError(t, err)
+		assert.Equal(t, exp, hybridDecrypt(t, key, ct, []byte("dex/dex-oidc")))
+	}
+
+	sealer, err = NewSealer(writeCert(t, key), ScopeClusterWide)
+	require.NoError(t, err)
+	res, err = (&Options{Mode: ModeSealed, Sealer: sealer}).Transform(testSecret(), "dex")
+	require.NoError(t, err)
+
+	meta := res["metadata"].(map[string]any)
+	assert.Equal(t, map[string]any{"sealedsecrets.bitnami.com/cluster-wide": "true"}, meta["annotations"])
+	ct, err := base64.StdEncoding.DecodeString(res["spec"].(map[string]any)["encryptedData"].(map[string]any)["clientID"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, "karavel", hybridDecrypt(t, key, ct, nil))
+
+	_, err = NewSealer(writeCert(t, key), "everywhere")
+	assert.Error(t, err)
+}
+
+func TestExternalReference(t *testing.T) {
+	opts := &Options{Mode: ModeExternal, External: &ExternalOptions{Store: "vault", KeyPrefix: "karavel/"}}
+	res, err := opts.Transform(testSecret(), "dex")
+	require.NoError(t, err)
+
+	assert.Equal(t, "ExternalSecret", res["kind"])
+	spec := res["spec"].(map[string]any)
+	assert.Equal(t, "1h", spec["refreshInterval"])
+	assert.Equal(t, map[string]any{"name": "vault", "kind": "ClusterSecretStore"}, spec["secretStoreRef"])
+	assert.Equal(t, []any{
+		map[string]any{"secretKey": "clientID", "remoteRef": map[string]any{"key": "karavel/dex/dex-oidc", "property": "clientID"}},
+		map[string]any{"secretKey": "clientSecret", "remoteRef": map[string]any{"key": "karavel/dex/dex-oidc", "property": "clientSecret"}},
+	}, spec["data"])
+	assert.NotContains(t, res, "data")
+}
+
+func TestOptions(t *testing.T) {
+	assert.True(t, IsSecret(testSecret()))
+	assert.False(t, IsSecret(map[string]any{"apiVersion": "v1", "kind": "ConfigMap"}))
+
+	opts := &Options{Mode: ModeSealed, Names: []string{"dex-oidc"}}
+	assert.True(t, opts.Matches("dex-oidc"))
+	assert.False(t, opts.Matches("dex-tls"))
+	assert.True(t, (&Options{}).Matches("dex-tls"))
+
+	// backends must be configured
+	_, err := opts.Transform(testSecret(), "dex")
+	assert.Error(t, err)
+	_, err = (&Options{Mode: ModeSealed}).Transform(map[string]any{"kind": "Secret"}, "dex")
+	assert.Error(t, err)
+}
@@ -0,0 +1,96 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selfupdate
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// NoticeInterval is how often the latest release is looked up for the new version notice
+const NoticeInterval = 24 * time.Hour
+
+type noticeCache struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    string    `json:"latest"`
+}
+
+// NoticeCachePath returns the file caching the last update check
+func NoticeCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "karavel", "update-check.json"), nil
+}
+
+// CheckNotice returns the latest release version if it is newer than current, or an empty string.
+// The latest release is looked up at most once per NoticeInterval, and cached in cachePath.
+func CheckNotice(ctx context.Context, src Source, current string, cachePath string, now time.Time) (string, error) {
+	cur, err := semver.NewVersion(current)
+	if err != nil {
+		// development builds have no comparable version
+		return "", nil
+	}
+
+	var cache noticeCache
+	if data, err := ioutil.ReadFile(cachePath); err == nil {
+		_ = json.Unmarshal(data, &cache)
+	}
+
+	if now.Sub(cache.CheckedAt) >= NoticeInterval {
+		// failed checks are recorded too, so that offline users are not slowed down on every run
+		cache.CheckedAt = now
+		rel, fetchErr := src.Latest(ctx)
+		if fetchErr == nil {
+			cache.Latest = rel.Version
+		}
+
+		if err := writeNoticeCache(cachePath, cache); err != nil {
+			return "", err
+		}
+		if fetchErr != nil {
+			return "", fetchErr
+		}
+	}
+
+	latest, err := semver.NewVersion(cache.Latest)
+	if err != nil {
+		return "", nil
+	}
+
+	if latest.GreaterThan(cur) {
+		return cache.Latest, nil
+	}
+	return "", nil
+}
+
+func writeNoticeCache(cachePath string, cache noticeCache) error {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0o755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(cachePath, data, 0o644)
+}
@@ -0,0 +1,135 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package selfupdate replaces the running CLI binary with a newer release published on GitHub
+package selfupdate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	DefaultRepo   = "karavel-io/cli"
+	DefaultApiUrl = "https://api.github.com"
+	ChecksumsFile = "checksums.txt"
+	SignatureExt  = ".sig"
+)
+
+// Release is a published CLI release
+type Release struct {
+	Version string
+	// Assets maps the release asset names to their download URLs
+	Assets map[string]string
+}
+
+// Source locates CLI releases on the GitHub releases API
+type Source struct {
+	ApiUrl string
+	Repo   string
+	Token  string
+}
+
+type ghRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name string `json:"name"`
+		Url  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// Latest returns the latest stable release
+func (s Source) Latest(ctx context.Context) (Release, error) {
+	return s.fetch(ctx, "latest")
+}
+
+// Get returns the release with the given version
+func (s Source) Get(ctx context.Context, version string) (Release, error) {
+	return s.fetch(ctx, "tags/v"+strings.TrimPrefix(version, "v"))
+}
+
+func (s Source) fetch(ctx context.Context, ref string) (Release, error) {
+	api := s.ApiUrl
+	if api == "" {
+		api = DefaultApiUrl
+	}
+	repo := s.Repo
+	if repo == "" {
+		repo = DefaultRepo
+	}
+
+	var rel Release
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/repos/%s/releases/%s", strings.TrimSuffix(api, "/"), repo, ref), nil)
+	if err != nil {
+		return rel, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return rel, fmt.Errorf("failed to fetch CLI release from GitHub API: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		return rel, fmt.Errorf("failed to fetch CLI release from GitHub API: %s", res.Status)
+	}
+
+	var ghrel ghRelease
+	if err := json.NewDecoder(res.Body).Decode(&ghrel); err != nil {
+		return rel, fmt.Errorf("failed to decode JSON response from GitHub API: %w", err)
+	}
+
+	rel.Version = strings.TrimPrefix(ghrel.TagName, "v")
+	rel.Assets = make(map[string]string, len(ghrel.Assets))
+	for _, a := range ghrel.Assets {
+		rel.Assets[a.Name] = a.Url
+	}
+	return rel, nil
+}
+
+// goreleaser archive name replacements, see .goreleaser.yml
+var archiveReplacements = map[string]string{
+	"darwin":  "Darwin",
+	"linux":   "Linux",
+	"windows": "Windows",
+	"386":     "i386",
+	"amd64":   "x86_64",
+}
+
+func replace(s string) string {
+	if r, ok := archiveReplacements[s]; ok {
+		return r
+	}
+	return s
+}
+
+// ArchiveName returns the name of the release archive for the given platform
+func ArchiveName(version string, goos string, goarch string) string {
+	return fmt.Sprintf("karavel_%s_%s_%s.tar.gz", strings.TrimPrefix(version, "v"), replace(goos), replace(goarch))
+}
+
+// BinaryName returns the name of the CLI executable inside the release archive
+func BinaryName(goos string) string {
+	if goos == "windows" {
+		return "karavel.exe"
+	}
+	return "karavel"
+}
@@ -0,0 +1,170 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selfupdate
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveName(t *testing.T) {
+	assert.Equal(t, "karavel_0.5.0_Linux_x86_64.tar.gz", ArchiveName("v0.5.0", "linux", "amd64"))
+	assert.Equal(t, "karavel_0.5.0_Darwin_arm64.tar.gz", ArchiveName("0.5.0", "darwin", "arm64"))
+	assert.Equal(t, "karavel_0.5.0_Windows_i386.tar.gz", ArchiveName("0.5.0", "windows", "386"))
+	assert.Equal(t, "karavel.exe", BinaryName("windows"))
+}
+
+func writeArchive(t *testing.T, path string, files map[string]string) {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "archive.tar.gz")
+	require.NoError(t, ioutil.WriteFile(file, []byte("content"), 0o644))
+
+	sums := []byte(fmt.Sprintf("%x  archive.tar.gz\n%x  other.tar.gz\n", sha256.Sum256([]byte("content")), sha256.Sum256([]byte("other"))))
+	assert.NoError(t, VerifyChecksum(sums, "archive.tar.gz", file))
+	assert.ErrorIs(t, VerifyChecksum(sums, "other.tar.gz", file), ErrChecksumMismatch)
+	assert.Error(t, VerifyChecksum(sums, "missing.tar.gz", file))
+}
+
+func TestInstall(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "karavel.tar.gz")
+	writeArchive(t, archive, map[string]string{
+		"README.md": "readme",
+		"karavel":   "new binary",
+	})
+
+	exe := filepath.Join(dir, "bin", "karavel")
+	require.NoError(t, os.MkdirAll(filepath.Dir(exe), 0o755))
+	require.NoError(t, ioutil.WriteFile(exe, []byte("old binary"), 0o755))
+
+	require.NoError(t, Install(archive, "karavel", exe))
+
+	content, err := ioutil.ReadFile(exe)
+	require.NoError(t, err)
+	assert.Equal(t, "new binary", string(content))
+
+	info, err := os.Stat(exe)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(filepath.Dir(exe))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	assert.Error(t, Install(archive, "missing", exe))
+	content, err = ioutil.ReadFile(exe)
+	require.NoError(t, err)
+	assert.Equal(t, "new binary", string(content))
+}
+
+func newReleaseServer(t *testing.T, tag string, calls *int) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		if r.URL.Path != "/repos/karavel-io/cli/releases/latest" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"tag_name": "%s", "assets": [{"name": "checksums.txt", "browser_download_url": "https://example.com/checksums.txt"}]}`, tag)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLatest(t *testing.T) {
+	var calls int
+	srv := newReleaseServer(t, "v0.5.0", &calls)
+
+	rel, err := Source{ApiUrl: srv.URL}.Latest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "0.5.0", rel.Version)
+	assert.Equal(t, map[string]string{"checksums.txt": "https://example.com/checksums.txt"}, rel.Assets)
+
+	_, err = Source{ApiUrl: srv.URL}.Get(context.Background(), "0.4.0")
+	assert.Error(t, err)
+}
+
+func TestCheckNotice(t *testing.T) {
+	var calls int
+	srv := newReleaseServer(t, "v0.5.0", &calls)
+	src := Source{ApiUrl: srv.URL}
+	cache := filepath.Join(t.TempDir(), "karavel", "update-check.json")
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	latest, err := CheckNotice(context.Background(), src, "0.4.2", cache, now)
+	require.NoError(t, err)
+	assert.Equal(t, "0.5.0", latest)
+	assert.Equal(t, 1, calls)
+
+	// the cached result is used within a day
+	latest, err = CheckNotice(context.Background(), src, "0.5.0", cache, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, latest)
+	assert.Equal(t, 1, calls)
+
+	_, err = CheckNotice(context.Background(), src, "0.4.2", cache, now.Add(NoticeInterval))
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	// development builds are never notified
+	latest, err = CheckNotice(context.Background(), src, "(devel)", cache, now)
+	require.NoError(t, err)
+	assert.Empty(t, latest)
+}
+
+func TestCheckNoticeOffline(t *testing.T) {
+	var calls int
+	srv := newReleaseServer(t, "v0.5.0", &calls)
+	srv.Close()
+
+	cache := filepath.Join(t.TempDir(), "update-check.json")
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	_, err := CheckNotice(context.Background(), Source{ApiUrl: srv.URL}, "0.4.2", cache, now)
+	assert.Error(t, err)
+
+	// the failed check is not retried within a day
+	latest, err := CheckNotice(context.Background(), Source{ApiUrl: srv.URL}, "0.4.2", cache, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, latest)
+}
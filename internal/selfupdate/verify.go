@@ -0,0 +1,81 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selfupdate
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// VerifyChecksum checks the SHA-256 digest of file against its entry in a goreleaser checksums file
+func VerifyChecksum(checksums []byte, name string, file string) error {
+	var expected string
+	sc := bufio.NewScanner(bytes.NewReader(checksums))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 2 && fields[1] == name {
+			expected = fields[0]
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+
+	if expected == "" {
+		return fmt.Errorf("no checksum found for %s", name)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != expected {
+		return fmt.Errorf("%w for %s: expected %s, got %s", ErrChecksumMismatch, name, expected, actual)
+	}
+	return nil
+}
+
+// VerifySignature checks a detached GPG signature using the gpg executable and the user's keyring
+func VerifySignature(file string, sig string) error {
+	gpg, err := exec.LookPath("gpg")
+	if err != nil {
+		return fmt.Errorf("the release is signed but gpg was not found in PATH, install it to verify the signature")
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(gpg, "--batch", "--verify", sig, file)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to verify release signature: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// Dir holds the local state of a project, which is not meant to be committed
+	Dir      = ".karavel"
+	Filename = "state"
+)
+
+// State records the inputs and outputs of the components of the last render,
+// so that the components that did not change can be skipped
+type State struct {
+	Components map[string]Component `json:"components"`
+}
+
+type Component struct {
+	// Inputs is the digest of everything the rendered files depend on
+	Inputs string `json:"inputs"`
+	// Outputs is the digest of the rendered files, as computed by HashDir
+	Outputs string `json:"outputs"`
+}
+
+func New() State {
+	return State{Components: map[string]Component{}}
+}
+
+// Path returns the location of the state file of the project in workdir
+func Path(workdir string) string {
+	return filepath.Join(workdir, Dir, Filename)
+}
+
+// Read loads a state file from disk. A missing file results in an empty state.
+func Read(filename string) (State, error) {
+	s := New()
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+
+	if err := json.Unmarshal(b, &s); err != nil {
+		return s, fmt.Errorf("failed to decode state file %s: %w", filename, err)
+	}
+
+	if s.Components == nil {
+		s.Components = map[string]Component{}
+	}
+	return s, nil
+}
+
+func (s *State) Write(filename string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	// the state is local to the machine, keep it out of git without touching the project .gitignore
+	if err := ioutil.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*\n"), 0o644); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, append(b, '\n'), 0o644)
+}
+
+// Fresh reports whether the component was last rendered from the same inputs, and its files in outdir were not changed since
+func (s *State) Fresh(name string, inputs string, outdir string) (bool, error) {
+	c, ok := s.Components[name]
+	if !ok || c.Inputs != inputs || c.Outputs == "" {
+		return false, nil
+	}
+
+	outputs, err := HashDir(outdir)
+	if err != nil {
+		return false, err
+	}
+	return outputs == c.Outputs, nil
+}
+
+// HashDir computes a digest of the paths and contents of the files in dir. A missing dir results in an empty digest.
+func HashDir(dir string) (string, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return "", nil
+	}
+
+	h := sha256.New()
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(h, "%s\x00", filepath.ToSlash(rel))
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		fh := sha256.New()
+		if _, err := io.Copy(fh, f); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(h, "%x\n", fh.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", dir, err)
+	}
+
+	return fmt.Sprintf("sha256:%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadWrite(t *testing.T) {
+	workdir := t.TempDir()
+	path := Path(workdir)
+
+	s, err := Read(path)
+	require.NoError(t, err)
+	assert.Empty(t, s.Components)
+
+	s.Components["dex"] = Component{Inputs: "sha256:in", Outputs: "sha256:out"}
+	require.NoError(t, s.Write(path))
+
+	read, err := Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, s, read)
+
+	ignore, err := os.ReadFile(filepath.Join(workdir, Dir, ".gitignore"))
+	require.NoError(t, err)
+	assert.Equal(t, "*\n", string(ignore))
+}
+
+func TestHashDir(t *testing.T) {
+	dir := t.TempDir()
+	h, err := HashDir(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, h)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yml"), []byte("a"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "b.yml"), []byte("b"), 0o644))
+
+	h1, err := HashDir(dir)
+	require.NoError(t, err)
+	assert.NotEmpty(t, h1)
+
+	// renaming a file changes the digest
+	require.NoError(t, os.Rename(filepath.Join(dir, "a.yml"), filepath.Join(dir, "c.yml")))
+	h2, err := HashDir(dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, h1, h2)
+
+	// and so does changing its content
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "c.yml"), []byte("c"), 0o644))
+	h3, err := HashDir(dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, h2, h3)
+}
+
+func TestFresh(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yml"), []byte("a"), 0o644))
+	outputs, err := HashDir(dir)
+	require.NoError(t, err)
+
+	s := New()
+	s.Components["dex"] = Component{Inputs: "in", Outputs: outputs}
+
+	for name, tt := range map[string]struct {
+		component string
+		inputs    string
+		want      bool
+	}{
+		"unchanged":      {component: "dex", inputs: "in", want: true},
+		"changed inputs": {component: "dex", inputs: "other"},
+		"unknown":        {component: "argocd", inputs: "in"},
+	} {
+		fresh, err := s.Fresh(tt.component, tt.inputs, dir)
+		require.NoError(t, err, name)
+		assert.Equal(t, tt.want, fresh, name)
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yml"), []byte("edited"), 0o644))
+	fresh, err := s.Fresh("dex", "in", dir)
+	require.NoError(t, err)
+	assert.False(t, fresh, "edited outputs")
+}
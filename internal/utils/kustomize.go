@@ -24,10 +24,70 @@ import (
 
 	"gopkg.in/yaml.v3"
 	"modernc.org/sortutil"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
-func RenderKustomizeFile(outdir string, resources []string, ignoreFn func(s string) bool) error {
+// KustomizeOptions customizes the resources listed in a kustomization file
+type KustomizeOptions struct {
+	Patches           []types.Patch
+	Images            []types.Image
+	CommonLabels      map[string]string
+	CommonAnnotations map[string]string
+}
+
+// apply sets the options on a kustomization, preparing it to be marshalled
+func (o *KustomizeOptions) apply(k *types.Kustomization) error {
+	if o != nil {
+		k.Patches = o.Patches
+		k.Images = o.Images
+		k.CommonLabels = o.CommonLabels
+		k.CommonAnnotations = o.CommonAnnotations
+	}
+
+	return k.FixKustomizationPreMarshalling()
+}
+
+// KustomizeResources runs kustomize in memory over a multi-document YAML stream, returning the resulting stream
+func KustomizeResources(resources []byte, opts *KustomizeOptions) ([]byte, error) {
+	const resourcesFile = "resources.yml"
+
+	kfile := types.Kustomization{
+		TypeMeta: types.TypeMeta{
+			APIVersion: types.KustomizationVersion,
+			Kind:       types.KustomizationKind,
+		},
+		Resources: []string{resourcesFile},
+	}
+	if err := opts.apply(&kfile); err != nil {
+		return nil, err
+	}
+
+	kb, err := yaml.Marshal(&kfile)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := filesys.MakeFsInMemory()
+	if err := fs.WriteFile(resourcesFile, resources); err != nil {
+		return nil, err
+	}
+	if err := fs.WriteFile(konfig.DefaultKustomizationFileName(), kb); err != nil {
+		return nil, err
+	}
+
+	k := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	res, err := k.Run(fs, ".")
+	if err != nil {
+		return nil, fmt.Errorf("kustomize build failed: %w", err)
+	}
+
+	return res.AsYaml()
+}
+
+func RenderKustomizeFile(outdir string, resources []string, ignoreFn func(s string) bool, opts *KustomizeOptions) error {
 	filename := filepath.Join(outdir, "kustomization.yml")
 	exists := false
 	info, err := os.Stat(filename)
@@ -64,7 +124,9 @@ func RenderKustomizeFile(outdir string, resources []string, ignoreFn func(s stri
 
 	kfile.Resources = resources
 
-	kfile.FixKustomizationPreMarshalling()
+	if err := opts.apply(&kfile); err != nil {
+		return fmt.Errorf("could not render %s: %w", filename, err)
+	}
 
 	// Cleanup paths and make sure they're using unix-style separators
 	// This is mostly useful for Windows, other OSs shouldn't worry about this
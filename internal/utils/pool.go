@@ -0,0 +1,110 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Errors aggregates the failures of independent tasks
+type Errors []error
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "%d errors occurred:", len(e))
+	for _, err := range e {
+		_, _ = fmt.Fprintf(&sb, "\n  * %s", err)
+	}
+	return sb.String()
+}
+
+// DefaultParallelism returns the parallelism used when none is requested
+func DefaultParallelism() int {
+	return runtime.NumCPU()
+}
+
+// ForEach calls fn for every item, running at most parallelism calls at the same time.
+// The first failure cancels the context passed to the calls still running and stops the scheduling of new ones.
+// ForEach waits for every call to return. A single failure is returned as is, several ones as Errors sorted by message.
+// Failures caused by the cancellation itself are not reported.
+func ForEach[T any](ctx context.Context, parallelism int, items []T, fn func(ctx context.Context, i int, item T) error) error {
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism()
+	}
+
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var errs Errors
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallelism)
+
+schedule:
+	for i, item := range items {
+		select {
+		case <-wctx.Done():
+			break schedule
+		case sem <- struct{}{}:
+		}
+
+		// a slot may be freed by the failure that cancelled the context
+		if wctx.Err() != nil {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, item T) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := fn(wctx, i, item); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				if wctx.Err() == nil || !errors.Is(err, context.Canceled) {
+					errs = append(errs, err)
+				}
+				cancel()
+			}
+		}(i, item)
+	}
+
+	wg.Wait()
+
+	switch len(errs) {
+	case 0:
+		return ctx.Err()
+	case 1:
+		return errs[0]
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Error() < errs[j].Error()
+	})
+	return errs
+}
@@ -0,0 +1,92 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEach(t *testing.T) {
+	items := make([]int, 20)
+	results := make([]int, len(items))
+	var running, peak int32
+	err := ForEach(context.Background(), 3, items, func(ctx context.Context, i int, _ int) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		results[i] = i * 2
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, peak, int32(3))
+	for i, r := range results {
+		assert.Equal(t, i*2, r)
+	}
+}
+
+func TestForEachErrors(t *testing.T) {
+	items := []string{"b", "a", "c"}
+	var started sync.WaitGroup
+	started.Add(len(items))
+
+	err := ForEach(context.Background(), len(items), items, func(ctx context.Context, _ int, item string) error {
+		started.Done()
+		started.Wait()
+		if item == "c" {
+			// stopped by the other failures, the cancellation is not reported
+			<-ctx.Done()
+			return fmt.Errorf("item %s: %w", item, ctx.Err())
+		}
+		return fmt.Errorf("item %s failed", item)
+	})
+
+	var errs Errors
+	assert.True(t, errors.As(err, &errs))
+	assert.EqualError(t, err, "2 errors occurred:\n  * item a failed\n  * item b failed")
+}
+
+func TestForEachStopsScheduling(t *testing.T) {
+	var calls int32
+	err := ForEach(context.Background(), 1, []int{1, 2, 3}, func(ctx context.Context, _ int, item int) error {
+		atomic.AddInt32(&calls, 1)
+		return fmt.Errorf("item %d failed", item)
+	})
+
+	assert.EqualError(t, err, "item 1 failed")
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestForEachParentCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := ForEach(ctx, 2, []int{1, 2}, func(ctx context.Context, _ int, _ int) error {
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,62 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package version
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+var (
+	ErrIncompatible   = errors.New("incompatible Karavel CLI version")
+	ErrUnknownVersion = errors.New("unknown Karavel CLI version")
+)
+
+// CheckConstraint verifies that the running CLI satisfies the given semver constraint, such as ">= 0.5, < 1.0".
+// Development builds, whose version cannot be compared, return ErrUnknownVersion.
+// Prerelease builds, such as snapshots and release candidates, are checked as the release they precede.
+func CheckConstraint(constraint string) error {
+	return checkConstraint(Get().Version, constraint)
+}
+
+func checkConstraint(ver string, constraint string) error {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return fmt.Errorf("invalid CLI version constraint '%s': %w", constraint, err)
+	}
+
+	v, err := semver.NewVersion(ver)
+	// builds from source get a v0.0.0 pseudo-version from the Go toolchain
+	if err != nil || (v.Major() == 0 && v.Minor() == 0 && v.Patch() == 0) {
+		return fmt.Errorf("%w %s, cannot check constraint '%s'", ErrUnknownVersion, ver, constraint)
+	}
+
+	// semver constraints never match prereleases, which would reject every snapshot and release candidate build.
+	// They are compared as the release they precede
+	if r, err := v.SetPrerelease(""); err == nil {
+		v = &r
+	}
+
+	if ok, errs := c.Validate(v); !ok {
+		msg := constraint
+		if len(errs) > 0 {
+			msg = errs[0].Error()
+		}
+		return fmt.Errorf("%w: %s", ErrIncompatible, msg)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckConstraint(t *testing.T) {
+	assert.NoError(t, checkConstraint("0.5.1", ">= 0.5, < 1.0"))
+	assert.NoError(t, checkConstraint("v0.5.1", "~0.5"))
+	assert.ErrorIs(t, checkConstraint("0.4.2", ">= 0.5, < 1.0"), ErrIncompatible)
+	assert.ErrorIs(t, checkConstraint("1.0.0", ">= 0.5, < 1.0"), ErrIncompatible)
+	assert.NoError(t, checkConstraint("0.4.2-edge", ">= 0.4"))
+	assert.NoError(t, checkConstraint("v0.5.0-rc.1", ">= 0.4, < 1.0"))
+	assert.ErrorIs(t, checkConstraint("1.0.0-rc.1", ">= 0.4, < 1.0"), ErrIncompatible)
+	assert.ErrorIs(t, checkConstraint("(devel)", ">= 0.5"), ErrUnknownVersion)
+	assert.ErrorIs(t, checkConstraint("v0.0.0-20220801101010-c19dfdcd04c5+dirty", ">= 0.5"), ErrUnknownVersion)
+	assert.Error(t, checkConstraint("0.5.0", "not a constraint"))
+}
@@ -29,14 +29,14 @@ var (
 )
 
 type Version struct {
-	Version       string
-	GitCommit     string
-	GitTreeState  string
-	GitCommitDate string
-	BuildDate     string
-	Arch          string
-	Os            string
-	GoVersion     string
+	Version       string `json:"version" yaml:"version"`
+	GitCommit     string `json:"gitCommit" yaml:"gitCommit"`
+	GitTreeState  string `json:"gitTreeState" yaml:"gitTreeState"`
+	GitCommitDate string `json:"gitCommitDate" yaml:"gitCommitDate"`
+	BuildDate     string `json:"buildDate" yaml:"buildDate"`
+	Arch          string `json:"arch" yaml:"arch"`
+	Os            string `json:"os" yaml:"os"`
+	GoVersion     string `json:"goVersion" yaml:"goVersion"`
 }
 
 func Get() Version {
@@ -0,0 +1,266 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/karavel-io/cli/internal/helmw"
+	"github.com/karavel-io/cli/internal/plan"
+	"github.com/karavel-io/cli/pkg/config"
+	"github.com/karavel-io/cli/pkg/logger"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+type ComponentsParams struct {
+	ConfigPath     string
+	KaravelVersion string
+	Unstable       bool
+}
+
+// CatalogueEntry describes a component available in the Karavel repositories
+type CatalogueEntry struct {
+	Name         string
+	Version      string
+	Description  string
+	Keywords     []string
+	Unstable     bool
+	Bootstrap    bool
+	Singleton    bool
+	Dependencies []string
+	Integrations map[string][]string
+}
+
+// ComponentDetails extends a CatalogueEntry with the chart's default values and README
+type ComponentDetails struct {
+	CatalogueEntry
+	Values string
+	Readme string
+}
+
+type catalogue struct {
+	version     string
+	stableUrl   string
+	unstableUrl string
+	stable      *repo.IndexFile
+	unstable    *repo.IndexFile
+}
+
+func loadCatalogue(ctx context.Context, params ComponentsParams) (catalogue, error) {
+	log := logger.FromContext(ctx)
+	cat := catalogue{version: params.KaravelVersion}
+
+	if cat.version == "" {
+		// only the declarations are needed, so that no secret is decrypted
+		log.Debug("Reading config file")
+		cfg, err := config.ReadDeclarations(log.Writer(), params.ConfigPath)
+		if err != nil {
+			return cat, fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		cat.version = cfg.Version
+		cat.stableUrl = cfg.HelmStableRepoUrl
+		cat.unstableUrl = cfg.HelmUnstableRepoUrl
+	} else {
+		cat.stableUrl = helmw.GetRepoUrl(cat.version, "")
+		cat.unstableUrl = helmw.GetRepoUrl("unstable", "")
+	}
+
+	log.Debugf("Loading Karavel %s components repository %s", cat.version, cat.stableUrl)
+	idx, err := helmw.LoadIndex(ctx, cat.stableUrl)
+	if err != nil {
+		return cat, fmt.Errorf("failed to load Karavel %s components repository: %w", cat.version, err)
+	}
+	cat.stable = idx
+
+	if params.Unstable {
+		log.Debugf("Loading Karavel unstable components repository %s", cat.unstableUrl)
+		idx, err := helmw.LoadIndex(ctx, cat.unstableUrl)
+		if err != nil {
+			return cat, fmt.Errorf("failed to load Karavel unstable components repository: %w", err)
+		}
+		cat.unstable = idx
+	}
+
+	return cat, nil
+}
+
+// find returns the requested version of a component, looking in the stable repository first
+func (cat *catalogue) find(name string, version string) (*repo.ChartVersion, bool, error) {
+	cv, err := cat.stable.Get(name, version)
+	if err == nil {
+		return cv, false, nil
+	}
+
+	if cat.unstable != nil {
+		if ucv, uerr := cat.unstable.Get(name, version); uerr == nil {
+			return ucv, true, nil
+		}
+	}
+
+	return nil, false, fmt.Errorf("component '%s' not found in Karavel %s: %w", name, cat.version, err)
+}
+
+// loadChart downloads the chart of the given component version
+func (cat *catalogue) loadChart(ctx context.Context, cv *repo.ChartVersion, unstable bool) (*chart.Chart, error) {
+	repoVersion, repoUrl := cat.version, cat.stableUrl
+	if unstable {
+		repoVersion, repoUrl = "unstable", cat.unstableUrl
+	}
+
+	ctx, err := addRepo(ctx, repoVersion, repoUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup Karavel components repository: %w", err)
+	}
+	defer helmw.Clean(ctx)
+
+	ch, err := helmw.LoadChart(ctx, cv.Name, cv.Version, unstable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load component '%s': %w", cv.Name, err)
+	}
+
+	return ch, nil
+}
+
+func (cat *catalogue) entries() ([]CatalogueEntry, error) {
+	var entries []CatalogueEntry
+	seen := make(map[string]bool)
+	for _, idx := range []*repo.IndexFile{cat.stable, cat.unstable} {
+		if idx == nil {
+			continue
+		}
+
+		unstable := idx == cat.unstable
+		for name, versions := range idx.Entries {
+			if seen[name] || len(versions) == 0 {
+				continue
+			}
+			seen[name] = true
+
+			e, err := newCatalogueEntry(versions[0], unstable)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, e)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+func newCatalogueEntry(cv *repo.ChartVersion, unstable bool) (CatalogueEntry, error) {
+	comp, err := plan.NewComponentFromChartMetadata(cv.Metadata, unstable)
+	if err != nil {
+		return CatalogueEntry{}, fmt.Errorf("failed to read metadata for component '%s': %w", cv.Name, err)
+	}
+
+	// the singleton annotation is passed to the chart like an integration, but is reported as a flag
+	integs := comp.IntegrationDependencies()
+	delete(integs, "karavel.io/singleton")
+
+	return CatalogueEntry{
+		Name:         cv.Name,
+		Version:      cv.Version,
+		Description:  cv.Description,
+		Keywords:     cv.Keywords,
+		Unstable:     unstable,
+		Bootstrap:    comp.IsBootstrap(),
+		Singleton:    comp.IsSingleton(),
+		Dependencies: comp.Dependencies(),
+		Integrations: integs,
+	}, nil
+}
+
+// ListComponents returns the latest version of every component available for the configured platform version
+func ListComponents(ctx context.Context, params ComponentsParams) ([]CatalogueEntry, error) {
+	cat, err := loadCatalogue(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return cat.entries()
+}
+
+// SearchComponents returns the components whose name, description or keywords contain the query
+func SearchComponents(ctx context.Context, params ComponentsParams, query string) ([]CatalogueEntry, error) {
+	entries, err := ListComponents(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return filterEntries(entries, query), nil
+}
+
+func filterEntries(entries []CatalogueEntry, query string) []CatalogueEntry {
+	query = strings.ToLower(query)
+	var res []CatalogueEntry
+	for _, e := range entries {
+		haystack := append([]string{e.Name, e.Description}, e.Keywords...)
+		for _, s := range haystack {
+			if strings.Contains(strings.ToLower(s), query) {
+				res = append(res, e)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// ShowComponent returns the details of a component, downloading its chart to read the default values and README
+func ShowComponent(ctx context.Context, params ComponentsParams, name string, version string) (ComponentDetails, error) {
+	var details ComponentDetails
+	cat, err := loadCatalogue(ctx, params)
+	if err != nil {
+		return details, err
+	}
+
+	cv, unstable, err := cat.find(name, version)
+	if err != nil {
+		return details, err
+	}
+
+	entry, err := newCatalogueEntry(cv, unstable)
+	if err != nil {
+		return details, err
+	}
+	details.CatalogueEntry = entry
+
+	ch, err := cat.loadChart(ctx, cv, unstable)
+	if err != nil {
+		return details, err
+	}
+
+	for _, f := range ch.Raw {
+		if f.Name == "values.yaml" {
+			details.Values = string(f.Data)
+		}
+	}
+
+	for _, f := range ch.Files {
+		if strings.EqualFold(f.Name, "README.md") {
+			details.Readme = string(f.Data)
+		}
+	}
+
+	return details, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+func TestFilterEntries(t *testing.T) {
+	entries := []CatalogueEntry{
+		{Name: "argocd", Description: "Declarative GitOps CD for Kubernetes"},
+		{Name: "dex", Description: "OpenID Connect identity provider", Keywords: []string{"sso", "oidc"}},
+		{Name: "grafana", Description: "Observability dashboards"},
+	}
+
+	names := func(ee []CatalogueEntry) []string {
+		var res []string
+		for _, e := range ee {
+			res = append(res, e.Name)
+		}
+		return res
+	}
+
+	assert.Equal(t, []string{"argocd"}, names(filterEntries(entries, "Argo")))
+	assert.Equal(t, []string{"dex"}, names(filterEntries(entries, "SSO")))
+	assert.Equal(t, []string{"grafana"}, names(filterEntries(entries, "dashboard")))
+	assert.Empty(t, filterEntries(entries, "velero"))
+}
+
+func TestNewCatalogueEntry(t *testing.T) {
+	e, err := newCatalogueEntry(&repo.ChartVersion{Metadata: &chart.Metadata{
+		Name:    "argocd",
+		Version: "0.3.0",
+		Annotations: map[string]string{
+			"karavel.io/singleton": "true",
+			"monitoring.enabled":   "prometheus",
+		},
+	}}, false)
+	require.NoError(t, err)
+
+	assert.True(t, e.Singleton)
+	assert.Equal(t, map[string][]string{"monitoring.enabled": {"prometheus"}}, e.Integrations)
+}
@@ -0,0 +1,80 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/karavel-io/cli/pkg/config"
+	"github.com/karavel-io/cli/pkg/logger"
+)
+
+type ConvertParams struct {
+	Source string
+	// Dest is the converted file. If empty the result is written to Stdout
+	Dest string
+	// Format defaults to the format of Dest, or to HCL for JSON and YAML sources and JSON for HCL ones
+	Format config.Format
+	Stdout io.Writer
+}
+
+// ConvertConfig translates a config file between the HCL, JSON and YAML formats. Included files are not converted
+func ConvertConfig(ctx context.Context, params ConvertParams) error {
+	log := logger.FromContext(ctx)
+
+	from := config.FormatOf(params.Source)
+	to := params.Format
+	if params.Dest != "" {
+		if to != "" && to != config.FormatOf(params.Dest) {
+			return fmt.Errorf("format '%s' does not match the extension of %s", to, params.Dest)
+		}
+		to = config.FormatOf(params.Dest)
+	}
+	if to == "" {
+		to = config.FormatHCL
+		if from == config.FormatHCL {
+			to = config.FormatJSON
+		}
+	}
+
+	src, err := os.ReadFile(params.Source)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	log.Debugf("Converting %s from %s to %s", params.Source, from, to)
+	out, err := config.Convert(src, params.Source, to)
+	if err != nil {
+		return err
+	}
+
+	if params.Dest == "" {
+		_, err := params.Stdout.Write(out)
+		return err
+	}
+
+	if _, err := os.Stat(params.Dest); err == nil {
+		return fmt.Errorf("%s already exists, remove it or omit the destination to print the result", params.Dest)
+	}
+	if err := os.WriteFile(params.Dest, out, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", params.Dest, err)
+	}
+
+	log.Infof("Converted %s to %s", params.Source, params.Dest)
+	return nil
+}
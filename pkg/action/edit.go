@@ -0,0 +1,292 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/karavel-io/cli/internal/prompt"
+	"github.com/karavel-io/cli/pkg/config"
+	"github.com/karavel-io/cli/pkg/logger"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+type AddParams struct {
+	ConfigPath string
+	Component  string
+	Name       string
+	Namespace  string
+	Version    string
+	// Yes skips confirmations, assuming a positive answer
+	Yes      bool
+	Prompter *prompt.Prompter
+}
+
+type RemoveParams struct {
+	ConfigPath string
+	Name       string
+	Yes        bool
+	Prompter   *prompt.Prompter
+}
+
+const unstablePrefix = "unstable:"
+
+var errNoConfirmation = fmt.Errorf("%w, run the command with --yes to skip confirmations", prompt.ErrNoInput)
+
+func AddComponent(ctx context.Context, params AddParams) error {
+	cpath := params.ConfigPath
+	log := logger.FromContext(ctx)
+
+	// only the declared names are needed, params are not evaluated so that secrets are not decrypted
+	log.Debug("Reading config file")
+	decls, err := config.ReadDeclarations(log.Writer(), cpath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	declared := make(map[string]bool)
+	for _, dc := range decls.Components {
+		declared[dc.Name] = true
+	}
+
+	name := params.Name
+	if name == "" {
+		name = params.Component
+	}
+	name = strings.ToLower(name)
+	if declared[name] {
+		return fmt.Errorf("component '%s' is already declared in %s", name, cpath)
+	}
+
+	version := params.Version
+	unstable := strings.HasPrefix(strings.ToLower(version), unstablePrefix)
+	if unstable {
+		version = version[len(unstablePrefix):]
+	}
+
+	cat, err := loadCatalogue(ctx, ComponentsParams{ConfigPath: cpath, Unstable: unstable})
+	if err != nil {
+		return err
+	}
+
+	queue := []AddParams{params}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+
+		cv, fromUnstable, err := cat.find(p.Component, version)
+		if err != nil {
+			return err
+		}
+		// dependencies are resolved to their latest version
+		version = ""
+
+		ch, err := cat.loadChart(ctx, cv, fromUnstable)
+		if err != nil {
+			return err
+		}
+
+		defaults, err := requiredParams(ch)
+		if err != nil {
+			return fmt.Errorf("failed to read required params for component '%s': %w", p.Component, err)
+		}
+
+		name := p.Name
+		if name == "" {
+			name = p.Component
+		}
+		name = strings.ToLower(name)
+
+		pinned := cv.Version
+		if fromUnstable {
+			pinned = unstablePrefix + pinned
+		}
+
+		log.Infof("Adding component '%s' %s to %s", cv.Name, pinned, decls.MainFile)
+		if err := config.AddComponent(decls.MainFile, config.ComponentBlock{
+			Name:          name,
+			ComponentName: cv.Name,
+			Namespace:     p.Namespace,
+			Version:       pinned,
+			Params:        defaults,
+		}); err != nil {
+			return err
+		}
+		declared[name] = true
+
+		entry, err := newCatalogueEntry(cv, fromUnstable)
+		if err != nil {
+			return err
+		}
+
+		var missing []string
+		for _, dep := range entry.Dependencies {
+			if !declared[dep] {
+				missing = append(missing, dep)
+			}
+		}
+
+		if len(missing) == 0 {
+			continue
+		}
+
+		ok, err := confirm(p.Prompter, p.Yes, fmt.Sprintf("Component '%s' requires %s. Add them too?", name, strings.Join(missing, ", ")))
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			log.Warnf("Component '%s' requires %s, rendering will fail until they are added", name, strings.Join(missing, ", "))
+			continue
+		}
+
+		for _, dep := range missing {
+			// mark as declared right away so that shared dependencies are only added once
+			declared[dep] = true
+			queue = append(queue, AddParams{Component: dep, Yes: p.Yes, Prompter: p.Prompter})
+		}
+	}
+
+	return nil
+}
+
+// RemoveComponent removes a component block from the config file declaring it. It works offline and without
+// the keys to decrypt secrets, the check for components depending on it is skipped if the catalogue cannot be loaded.
+func RemoveComponent(ctx context.Context, params RemoveParams) error {
+	cpath := params.ConfigPath
+	name := strings.ToLower(params.Name)
+	log := logger.FromContext(ctx)
+
+	log.Debug("Reading config file")
+	decls, err := config.ReadDeclarations(log.Writer(), cpath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	file := ""
+	for _, dc := range decls.Components {
+		if dc.Name == name {
+			file = dc.File
+		}
+	}
+	if file == "" {
+		return fmt.Errorf("component '%s' is not declared in %s", name, cpath)
+	}
+
+	dependents, err := findDependents(ctx, cpath, decls, name)
+	if err != nil {
+		log.Warnf("Could not check which components depend on '%s': %s", name, err)
+	}
+
+	if len(dependents) > 0 {
+		log.Warnf("Components %s depend on '%s', rendering will fail until they are removed too", strings.Join(dependents, ", "), name)
+		ok, err := confirm(params.Prompter, params.Yes, fmt.Sprintf("Remove component '%s' anyway?", name))
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return nil
+		}
+	}
+
+	log.Infof("Removing component '%s' from %s", name, file)
+	return config.RemoveComponent(file, name)
+}
+
+// findDependents lists the declared components that depend on the named one, according to the catalogue
+func findDependents(ctx context.Context, cpath string, decls config.Declarations, name string) ([]string, error) {
+	log := logger.FromContext(ctx)
+
+	cat, err := loadCatalogue(ctx, ComponentsParams{ConfigPath: cpath, Unstable: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var dependents []string
+	for _, dc := range decls.Components {
+		if dc.Name == name {
+			continue
+		}
+
+		chartName := dc.Name
+		if dc.ComponentName != "" {
+			chartName = dc.ComponentName
+		}
+
+		cv, unstable, err := cat.find(chartName, dc.Version)
+		if err != nil {
+			log.Debugf("Could not check dependencies of component '%s': %s", dc.Name, err)
+			continue
+		}
+
+		entry, err := newCatalogueEntry(cv, unstable)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, dep := range entry.Dependencies {
+			if dep == name {
+				dependents = append(dependents, dc.Name)
+			}
+		}
+	}
+	return dependents, nil
+}
+
+func confirm(p *prompt.Prompter, yes bool, question string) (bool, error) {
+	if yes {
+		return true, nil
+	}
+
+	if p == nil {
+		return false, errNoConfirmation
+	}
+
+	ok, err := p.Confirm(question, false)
+	if errors.Is(err, prompt.ErrNoInput) {
+		return false, errNoConfirmation
+	}
+	return ok, err
+}
+
+// requiredParams returns the default values of the params the chart's values schema marks as required
+func requiredParams(ch *chart.Chart) (map[string]any, error) {
+	if len(ch.Schema) == 0 {
+		return nil, nil
+	}
+
+	var schema struct {
+		Required []string `json:"required"`
+	}
+	if err := json.Unmarshal(ch.Schema, &schema); err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]any, len(schema.Required))
+	for _, r := range schema.Required {
+		// the namespace is managed through the component block attribute
+		if r == "namespace" {
+			continue
+		}
+		params[r] = ch.Values[r]
+	}
+	return params, nil
+}
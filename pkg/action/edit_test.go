@@ -0,0 +1,97 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestRequiredParams(t *testing.T) {
+	ch := &chart.Chart{
+		Schema: []byte(`{"required": ["namespace", "hostname", "storage"]}`),
+		Values: map[string]any{
+			"namespace": "dex",
+			"hostname":  "dex.example.com",
+			"replicas":  1,
+		},
+	}
+
+	params, err := requiredParams(ch)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"hostname": "dex.example.com",
+		"storage":  nil,
+	}, params)
+
+	params, err = requiredParams(&chart.Chart{})
+	assert.NoError(t, err)
+	assert.Empty(t, params)
+}
+
+func TestAddComponentWithoutSecretKeys(t *testing.T) {
+	repoUrl := newTestRepository(t)
+	cpath := filepath.Join(t.TempDir(), "karavel.hcl")
+	// the secrets file does not exist, so it cannot be decrypted
+	require.NoError(t, os.WriteFile(cpath, []byte(`
+version       = "1970.1"
+stable_repo   = "`+repoUrl+`"
+unstable_repo = "`+repoUrl+`"
+
+component "dex" {
+  clientSecret = sops("secrets/dex.enc.yaml", "clientSecret")
+}
+`), 0o644))
+
+	require.NoError(t, AddComponent(testContext(), AddParams{ConfigPath: cpath, Component: "web", Yes: true}))
+
+	content, err := os.ReadFile(cpath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `component "web"`)
+
+	assert.ErrorContains(t, AddComponent(testContext(), AddParams{ConfigPath: cpath, Component: "dex", Yes: true}), "already declared")
+}
+
+func TestRemoveComponentOffline(t *testing.T) {
+	dir := t.TempDir()
+	cpath := filepath.Join(dir, "karavel.hcl")
+	// neither the secrets file nor the repository exist
+	require.NoError(t, os.WriteFile(cpath, []byte(`
+version     = "1970.1"
+stable_repo = "http://127.0.0.1:1"
+
+component "dex" {
+  clientSecret = sops("secrets/dex.enc.yaml", "clientSecret")
+}
+
+component "argocd" {
+  namespace = "argocd"
+}
+`), 0o644))
+
+	require.NoError(t, RemoveComponent(testContext(), RemoveParams{ConfigPath: cpath, Name: "dex", Yes: true}))
+
+	content, err := os.ReadFile(cpath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "dex")
+	assert.Contains(t, string(content), `component "argocd"`)
+
+	assert.ErrorContains(t, RemoveComponent(testContext(), RemoveParams{ConfigPath: cpath, Name: "dex", Yes: true}), "is not declared")
+}
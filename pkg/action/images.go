@@ -0,0 +1,92 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/karavel-io/cli/internal/helmw"
+	"github.com/karavel-io/cli/internal/plan"
+	"github.com/karavel-io/cli/pkg/config"
+	"github.com/karavel-io/cli/pkg/logger"
+)
+
+type ImagesParams struct {
+	ConfigPath string
+}
+
+// ComponentImages lists the container images rendered by a component
+type ComponentImages struct {
+	Component string       `json:"component"`
+	Images    []plan.Image `json:"images"`
+}
+
+// ListImages renders the project components in memory and returns the container images they use, sorted by component name
+func ListImages(ctx context.Context, params ImagesParams) ([]ComponentImages, error) {
+	log := logger.FromContext(ctx)
+
+	ctx, _, p, err := loadPlan(ctx, params.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	defer helmw.Clean(ctx)
+
+	var res []ComponentImages
+	for _, c := range p.Components() {
+		log.Debugf("Listing images of component %s", c.DebugLabel())
+		images, err := c.Images(ctx, log)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ComponentImages{Component: c.Name(), Images: images})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Component < res[j].Component
+	})
+	return res, nil
+}
+
+// loadPlan reads the config, registers the components repositories and validates the render plan.
+// The caller must clean the returned context with helmw.Clean.
+func loadPlan(ctx context.Context, cpath string) (context.Context, *config.Config, *plan.Plan, error) {
+	log := logger.FromContext(ctx)
+
+	log.Debug("Reading config file")
+	cfg, err := config.ReadFrom(log.Writer(), cpath)
+	if err != nil {
+		return ctx, nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	ctx, err = setupRepositories(ctx, &cfg)
+	if err != nil {
+		return ctx, nil, nil, err
+	}
+
+	p, err := plan.NewFromConfig(ctx, &cfg, 0)
+	if err != nil {
+		helmw.Clean(ctx)
+		return ctx, nil, nil, fmt.Errorf("failed to instantiate render plan from config: %w", err)
+	}
+
+	if err := p.Validate(); err != nil {
+		helmw.Clean(ctx)
+		return ctx, nil, nil, err
+	}
+
+	return ctx, &cfg, p, nil
+}
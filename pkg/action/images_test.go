@@ -0,0 +1,50 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/karavel-io/cli/internal/plan"
+)
+
+func TestListImagesKustomizeOverride(t *testing.T) {
+	dir := newTestProject(t, newTestRepository(t))
+	cpath := filepath.Join(dir, "karavel.hcl")
+
+	appendConfig(t, cpath, `
+component "api" {
+  component = "web"
+
+  kustomize {
+    image "docker.io/library/nginx" {
+      new_name = "registry.example.com/nginx"
+      new_tag  = "1.24"
+    }
+  }
+}
+`)
+
+	res, err := ListImages(testContext(), ImagesParams{ConfigPath: cpath})
+	require.NoError(t, err)
+	assert.Equal(t, []ComponentImages{
+		{Component: "api", Images: []plan.Image{{Name: "registry.example.com/nginx:1.24"}}},
+		{Component: "web", Images: []plan.Image{{Name: "docker.io/library/nginx:1.23"}}},
+	}, res)
+}
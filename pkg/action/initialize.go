@@ -21,9 +21,10 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/template"
 
-	"github.com/karavel-io/cli/internal/github"
+	"github.com/karavel-io/cli/internal/prompt"
 	"github.com/karavel-io/cli/pkg/logger"
 )
 
@@ -32,7 +33,13 @@ type InitParams struct {
 	Filename       string
 	KaravelVersion string
 	Force          bool
-	GitHubRepo     string
+	// ReleaseSource lists the platform releases, see release.Parse for the supported formats
+	ReleaseSource string
+	// Interactive asks for the version, preset, domain and namespaces instead of writing an empty config file
+	Interactive bool
+	// Template is a local directory or a git repository URL containing a starter kit to copy in the workdir
+	Template string
+	Prompter *prompt.Prompter
 }
 
 const cfgTpl = `version = "{{ . }}"
@@ -50,11 +57,6 @@ const cfgTpl = `version = "{{ . }}"
 #  }
 `
 
-const (
-	githubRepo      = "karavel-io/platform"
-	githubApiURLTpl = "https://api.github.com/repos/%s/tags"
-)
-
 func Initialize(ctx context.Context, params InitParams) error {
 	workdir := params.Workdir
 	ver := params.KaravelVersion
@@ -62,52 +64,131 @@ func Initialize(ctx context.Context, params InitParams) error {
 	force := params.Force
 
 	log := logger.FromContext(ctx)
-	log.Infof("Initializing new Karavel %s project at %s", ver, workdir)
-	log.Info()
+
+	if params.Interactive && params.Prompter == nil {
+		return prompt.ErrNoInput
+	}
 
 	if err := os.MkdirAll(workdir, 0o755); err != nil {
 		return err
 	}
 
 	filedst := filepath.Join(workdir, filename)
-	info, err := os.Stat(filedst)
-	if err != nil && !os.IsNotExist(err) {
-		return err
-	}
+	if params.Template == "" {
+		info, err := os.Stat(filedst)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
 
-	if info != nil && !force {
-		return fmt.Errorf("Karavel config file %s already exists", filename)
-	}
+		if info != nil && !force {
+			return fmt.Errorf("Karavel config file %s already exists", filename)
+		}
 
-	if info != nil && force {
-		log.Warnf("Karavel config file %s already exists and will be overwritten", filename)
+		if info != nil && force {
+			log.Warnf("Karavel config file %s already exists and will be overwritten", filename)
+		}
 	}
 
+	var err error
 	if ver == "" || ver == "latest" {
-		repo := params.GitHubRepo
-		if repo == "" {
-			repo = githubRepo
+		if params.Interactive {
+			ver, err = selectVersion(ctx, params.Prompter, params.ReleaseSource)
+		} else {
+			ver, err = fetchLatestVersion(ctx, params.ReleaseSource)
+		}
+		if err != nil {
+			return err
 		}
+	}
 
-		apiUrl := fmt.Sprintf(githubApiURLTpl, repo)
-		log.Infof("Fetching latest release version for GitHub repo %s", repo)
-		ver, err = github.FetchLatestRelease(ctx, apiUrl)
+	log.Infof("Initializing new Karavel %s project at %s", ver, workdir)
+	log.Info()
+
+	if params.Template != "" {
+		dir, cleanup, err := fetchTemplate(ctx, params.Template)
+		defer cleanup()
 		if err != nil {
 			return err
 		}
+
+		log.Infof("Copying template %s to %s", params.Template, workdir)
+		return copyTemplate(log, dir, workdir, templateData{Version: ver}, force)
 	}
 
-	cfg, err := template.New("karavel.hcl").Parse(cfgTpl)
+	var content []byte
+	if params.Interactive {
+		content, err = runWizard(params.Prompter, ver)
+	} else {
+		content, err = renderEmptyConfig(ver)
+	}
 	if err != nil {
 		return err
 	}
 
+	log.Info()
+	log.Infof("Writing config file to %s", filedst)
+	return ioutil.WriteFile(filedst, content, 0o655)
+}
+
+func renderEmptyConfig(ver string) ([]byte, error) {
+	cfg, err := template.New("karavel.hcl").Parse(cfgTpl)
+	if err != nil {
+		return nil, err
+	}
+
 	var buf bytes.Buffer
 	if err = cfg.Execute(&buf, ver); err != nil {
-		return err
+		return nil, err
 	}
+	return buf.Bytes(), nil
+}
 
-	log.Info()
-	log.Infof("Writing config file to %s", filedst)
-	return ioutil.WriteFile(filedst, buf.Bytes(), 0o655)
+// selectVersion asks which platform release to use, defaulting to the latest
+func selectVersion(ctx context.Context, p *prompt.Prompter, source string) (string, error) {
+	versions, err := ListVersions(ctx, source)
+	if err != nil {
+		return "", err
+	}
+
+	i, err := p.Select("Karavel Container Platform version", versions, 0)
+	if err != nil {
+		return "", err
+	}
+	return versions[i], nil
+}
+
+// runWizard asks for a preset, a domain and the components namespaces, and renders the resulting config file
+func runWizard(p *prompt.Prompter, ver string) ([]byte, error) {
+	options := make([]string, len(presets))
+	for i, pr := range presets {
+		options[i] = fmt.Sprintf("%s: %s", pr.name, pr.description)
+	}
+
+	i, err := p.Select("Preset", options, 0)
+	if err != nil {
+		return nil, err
+	}
+	pr := presets[i]
+
+	domain, err := p.Input("Domain the platform UIs will be exposed under (e.g. example.com)", "")
+	if err != nil {
+		return nil, err
+	}
+	domain = strings.Trim(strings.TrimSpace(domain), ".")
+
+	cfg := presetConfig{Version: ver}
+	for _, name := range pr.components {
+		ns, err := p.Input(fmt.Sprintf("Namespace for component '%s'", name), name)
+		if err != nil {
+			return nil, err
+		}
+
+		c := presetComponent{Name: name, Namespace: ns}
+		if domain != "" && uiComponents[name] {
+			c.Hostname = name + "." + domain
+		}
+		cfg.Components = append(cfg.Components, c)
+	}
+
+	return renderPreset(cfg), nil
 }
@@ -0,0 +1,122 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/karavel-io/cli/internal/prompt"
+	"github.com/karavel-io/cli/pkg/config"
+	"github.com/karavel-io/cli/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunWizard(t *testing.T) {
+	// minimal preset, example.com domain, custom namespace for argocd only
+	p := prompt.New(strings.NewReader("1\nexample.com.\ngitops\n\n\n"), ioutil.Discard)
+
+	content, err := runWizard(p, "2022.1")
+	require.NoError(t, err)
+
+	cpath := filepath.Join(t.TempDir(), "karavel.hcl")
+	require.NoError(t, ioutil.WriteFile(cpath, content, 0o644))
+
+	cfg, err := config.ReadFrom(ioutil.Discard, cpath)
+	require.NoError(t, err)
+	assert.Equal(t, "2022.1", cfg.Version)
+	require.Len(t, cfg.Components, len(minimalComponents))
+
+	argo := cfg.Components[0]
+	assert.Equal(t, "argocd", argo.Name)
+	assert.Equal(t, "gitops", argo.Namespace)
+	assert.Contains(t, string(content), `hostname  = "argocd.example.com"`)
+
+	certManager := cfg.Components[1]
+	assert.Equal(t, "cert-manager", certManager.Name)
+	assert.Equal(t, "cert-manager", certManager.Namespace)
+}
+
+func TestRunWizardEscaping(t *testing.T) {
+	// user input that is not valid inside a plain HCL string
+	p := prompt.New(strings.NewReader("1\nexample.com\ngit\"ops\\${var}\n%{ if true }\n\n"), ioutil.Discard)
+
+	content, err := runWizard(p, "2022.1")
+	require.NoError(t, err)
+
+	cpath := filepath.Join(t.TempDir(), "karavel.hcl")
+	require.NoError(t, ioutil.WriteFile(cpath, content, 0o644))
+
+	cfg, err := config.ReadFrom(ioutil.Discard, cpath)
+	require.NoError(t, err)
+	require.Len(t, cfg.Components, len(minimalComponents))
+	assert.Equal(t, `git"ops\${var}`, cfg.Components[0].Namespace)
+	assert.Equal(t, "%{ if true }", cfg.Components[1].Namespace)
+}
+
+func TestCopyTemplate(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, ".git"), 0o755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, ".git", "HEAD"), []byte("ref: refs/heads/main"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "docs"), 0o755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "docs", "README.md"), []byte("{{ .Version }}"), 0o644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "karavel.hcl.tmpl"), []byte(`version = "{{ .Version }}"`), 0o644))
+
+	log := logger.New(logger.LvlError)
+	dst := t.TempDir()
+	require.NoError(t, copyTemplate(log, src, dst, templateData{Version: "2022.1"}, false))
+
+	content, err := ioutil.ReadFile(filepath.Join(dst, "karavel.hcl"))
+	require.NoError(t, err)
+	assert.Equal(t, `version = "2022.1"`, string(content))
+
+	// only .tmpl files are rendered
+	content, err = ioutil.ReadFile(filepath.Join(dst, "docs", "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "{{ .Version }}", string(content))
+
+	assert.NoDirExists(t, filepath.Join(dst, ".git"))
+
+	assert.Error(t, copyTemplate(log, src, dst, templateData{Version: "2022.2"}, false))
+	assert.NoError(t, copyTemplate(log, src, dst, templateData{Version: "2022.2"}, true))
+}
+
+func TestCopyTemplateFailure(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "docs"), 0o755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "docs", "README.md"), []byte("docs"), 0o644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "karavel.hcl.tmpl"), []byte(`version = "{{ .Version }}"`), 0o644))
+
+	log := logger.New(logger.LvlError)
+
+	// a conflict on a later file leaves the project untouched
+	dst := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dst, "karavel.hcl"), []byte("mine"), 0o644))
+	assert.ErrorContains(t, copyTemplate(log, src, dst, templateData{Version: "2022.1"}, false), "karavel.hcl already exists")
+	assert.NoDirExists(t, filepath.Join(dst, "docs"))
+
+	// and so does a broken template
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "values.yml.tmpl"), []byte("{{ .Missing }}"), 0o644))
+	dst = t.TempDir()
+	assert.ErrorContains(t, copyTemplate(log, src, dst, templateData{Version: "2022.1"}, false), "failed to render template file values.yml")
+	entries, err := os.ReadDir(dst)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/karavel-io/cli/internal/helmw"
+	"github.com/karavel-io/cli/internal/inventory"
+	"github.com/karavel-io/cli/internal/lockfile"
+	"github.com/karavel-io/cli/internal/version"
+	"github.com/karavel-io/cli/pkg/config"
+	"github.com/karavel-io/cli/pkg/logger"
+)
+
+type InventoryParams struct {
+	ConfigPath string
+}
+
+// Inventory renders the project components in memory and lists the charts, container images and CRDs they ship
+func Inventory(ctx context.Context, params InventoryParams) (inventory.Inventory, error) {
+	log := logger.FromContext(ctx)
+	inv := inventory.Inventory{
+		Tool:      version.Get().Version,
+		Timestamp: time.Now(),
+	}
+
+	ctx, cfg, p, err := loadPlan(ctx, params.ConfigPath)
+	if err != nil {
+		return inv, err
+	}
+	defer helmw.Clean(ctx)
+
+	// images pinned by the last render are reported with their digest
+	lock, err := lockfile.Read(filepath.Join(config.ProjectDir(params.ConfigPath), lockfile.Filename))
+	if err != nil {
+		return inv, err
+	}
+
+	inv.Platform = cfg.Version
+	for _, c := range p.Components() {
+		log.Debugf("Collecting inventory of component %s", c.DebugLabel())
+		// the deployed documents, with the kustomize image overrides and patches applied
+		docs, err := c.Manifests(ctx, log)
+		if err != nil {
+			return inv, err
+		}
+
+		repo := cfg.HelmStableRepoUrl
+		if c.IsUnstable() {
+			repo = cfg.HelmUnstableRepoUrl
+		}
+
+		ic := inventory.Component{
+			Name:         c.Name(),
+			Chart:        c.ComponentName(),
+			Version:      c.Version(),
+			Repository:   repo,
+			Namespace:    c.Namespace(),
+			Dependencies: c.Dependencies(),
+		}
+		ic.Collect(docs)
+		for i, image := range ic.Images {
+			if d, ok := lock.Images[image]; ok {
+				ic.Images[i] = image + "@" + d
+			}
+		}
+		inv.Components = append(inv.Components, ic)
+	}
+
+	sort.Slice(inv.Components, func(i, j int) bool {
+		return inv.Components[i].Name < inv.Components[j].Name
+	})
+	return inv, nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type preset struct {
+	name        string
+	description string
+	components  []string
+}
+
+var (
+	minimalComponents       = []string{"argocd", "cert-manager", "external-secrets"}
+	observabilityComponents = append(append([]string{}, minimalComponents...), "prometheus", "grafana", "loki", "tempo")
+	fullComponents          = append(append([]string{}, observabilityComponents...), "ingress-nginx", "external-dns", "dex", "velero")
+)
+
+var presets = []preset{
+	{
+		name:        "minimal",
+		description: "Minimal GitOps: ArgoCD, cert-manager and external-secrets",
+		components:  minimalComponents,
+	},
+	{
+		name:        "observability",
+		description: "Minimal GitOps, plus metrics, dashboards, logs and traces",
+		components:  observabilityComponents,
+	},
+	{
+		name:        "full",
+		description: "Observability, plus ingress, DNS, SSO and backups",
+		components:  fullComponents,
+	},
+}
+
+// components exposing a web UI, which get a hostname under the project domain
+var uiComponents = map[string]bool{
+	"argocd":  true,
+	"dex":     true,
+	"grafana": true,
+}
+
+const presetHeader = `#  For a list of available components consult https://platform.karavel.io/components/
+#  or run 'karavel components list'
+`
+
+type presetComponent struct {
+	Name      string
+	Namespace string
+	Hostname  string
+}
+
+type presetConfig struct {
+	Version    string
+	Components []presetComponent
+}
+
+// renderPreset writes the config file of a preset, quoting the values the user typed as HCL strings
+func renderPreset(cfg presetConfig) []byte {
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+	body.SetAttributeValue("version", cty.StringVal(cfg.Version))
+	body.AppendNewline()
+	body.AppendUnstructuredTokens(hclwrite.Tokens{{Type: hclsyntax.TokenComment, Bytes: []byte(presetHeader)}})
+
+	for _, c := range cfg.Components {
+		body.AppendNewline()
+		b := body.AppendNewBlock("component", []string{c.Name}).Body()
+		b.SetAttributeValue("namespace", cty.StringVal(c.Namespace))
+		if c.Hostname != "" {
+			b.SetAttributeValue("hostname", cty.StringVal(c.Hostname))
+		}
+	}
+
+	return hclwrite.Format(f.Bytes())
+}
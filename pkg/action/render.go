@@ -16,7 +16,9 @@ package action
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
@@ -28,16 +30,36 @@ import (
 	argocd "github.com/karavel-io/cli/internal/argo"
 	"github.com/karavel-io/cli/internal/gitutils"
 	"github.com/karavel-io/cli/internal/helmw"
+	"github.com/karavel-io/cli/internal/lockfile"
+	"github.com/karavel-io/cli/internal/oci"
 	"github.com/karavel-io/cli/internal/plan"
+	"github.com/karavel-io/cli/internal/postrender"
+	"github.com/karavel-io/cli/internal/state"
 	"github.com/karavel-io/cli/internal/utils"
 	"github.com/karavel-io/cli/internal/utils/predicate"
+	"github.com/karavel-io/cli/internal/version"
 	"github.com/karavel-io/cli/pkg/config"
 	"github.com/karavel-io/cli/pkg/logger"
 )
 
+// renderedPaths are the project paths written by a render, relative to the project directory
+var renderedPaths = []string{"vendor", "applications", "projects", "kustomization.yml", lockfile.Filename}
+
 type RenderParams struct {
 	ConfigPath string
 	SkipGit    bool
+	GitRemote  string
+	Commit     bool
+	// PinDigests pins the container images to their digest, reusing the ones cached in the lockfile
+	PinDigests bool
+	// Output is either dir, to render the project files, stdout or file:<path> to write a single YAML stream
+	Output string
+	// Stdout receives the YAML stream with the stdout output
+	Stdout io.Writer
+	// Force renders every component, ignoring the state of the last render
+	Force bool
+	// Parallelism caps the number of components loaded and rendered at the same time. Defaults to the number of CPUs
+	Parallelism int
 }
 
 func addRepo(ctx context.Context, version string, url string) (context.Context, error) {
@@ -56,41 +78,126 @@ func addRepo(ctx context.Context, version string, url string) (context.Context,
 	return ctx, nil
 }
 
+// setupRepositories registers the Karavel components repositories for the platform version in the config,
+// checking that the CLI version is supported by the stable repository
+func setupRepositories(ctx context.Context, cfg *config.Config) (context.Context, error) {
+	log := logger.FromContext(ctx)
+
+	log.Debugf("Karavel Container Platform version %s", cfg.Version)
+	log.Debugf("Updating Karavel components stable repository %s", cfg.HelmStableRepoUrl)
+	ctx, err := addRepo(ctx, cfg.Version, cfg.HelmStableRepoUrl)
+	if err != nil {
+		return ctx, fmt.Errorf("failed to setup Karavel stable components repository: %w", err)
+	}
+
+	idx, err := helmw.RepositoryIndex(ctx, helmw.HelmRepoName)
+	if err != nil {
+		return ctx, fmt.Errorf("failed to read Karavel stable components repository index: %w", err)
+	}
+
+	if min := idx.Annotations[helmw.MinCliVersionAnnotation]; min != "" {
+		if err := checkCliVersion(log, ">= "+min, fmt.Sprintf("Karavel Container Platform %s", cfg.Version)); err != nil {
+			return ctx, err
+		}
+	}
+
+	log.Debugf("Updating Karavel components unstable repository %s", cfg.HelmUnstableRepoUrl)
+	ctx, err = addRepo(ctx, "unstable", cfg.HelmUnstableRepoUrl)
+	if err != nil {
+		return ctx, fmt.Errorf("failed to setup Karavel unstable components repository: %w", err)
+	}
+
+	return ctx, nil
+}
+
 func Render(ctx context.Context, params RenderParams) error {
+	log := logger.FromContext(ctx)
+	log.Infof("Rendering new Karavel project with config file %s", params.ConfigPath)
+
+	cfg, err := readRenderConfig(log, params.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := params.output(); err != nil {
+		return err
+	}
+
+	ctx, err = setupRepositories(ctx, &cfg)
+	if err != nil {
+		return err
+	}
+
+	defer helmw.Clean(ctx)
+
+	return render(ctx, &cfg, params)
+}
+
+// readRenderConfig reads the config file, checking that the CLI satisfies its version constraint
+func readRenderConfig(log logger.Logger, cpath string) (config.Config, error) {
+	log.Debug("Reading config file")
+	cfg, err := config.ReadFrom(log.Writer(), cpath)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if cfg.CliVersion != "" {
+		if err := checkCliVersion(log, cfg.CliVersion, fmt.Sprintf("config file %s", cpath)); err != nil {
+			return cfg, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// output parses the Output param, checking that it can be used along with the other params
+func (p RenderParams) output() (file string, stream bool, err error) {
+	file, stream, err = parseOutput(p.Output)
+	if err != nil {
+		return "", false, err
+	}
+
+	if stream && p.Commit {
+		return "", false, fmt.Errorf("rendered files cannot be committed with output '%s'", p.Output)
+	}
+
+	return file, stream, nil
+}
+
+// render renders the project described by cfg, whose components repositories must be registered in the context
+func render(ctx context.Context, cfg *config.Config, params RenderParams) error {
 	cpath := params.ConfigPath
 	skipGit := params.SkipGit
-	workdir := filepath.Dir(cpath)
+	workdir := config.ProjectDir(cpath)
 	vendorDir := filepath.Join(workdir, "vendor")
 	appsDir := filepath.Join(workdir, "applications")
 	projsDir := filepath.Join(workdir, "projects")
 	argoEnabled := true
 
 	log := logger.FromContext(ctx)
-	log.Infof("Rendering new Karavel project with config file %s", cpath)
 
-	log.Debug("Reading config file")
-	cfg, err := config.ReadFrom(log.Writer(), cpath)
+	outfile, stream, err := params.output()
 	if err != nil {
-		return fmt.Errorf("failed to read config file: %w", err)
+		return err
 	}
 
-	log.Debugf("Karavel Container Platform version %s", cfg.Version)
-	log.Debugf("Updating Karavel components stable repository %s", cfg.HelmStableRepoUrl)
-	ctx, err = addRepo(ctx, cfg.Version, cfg.HelmStableRepoUrl)
-	if err != nil {
-		return fmt.Errorf("failed to setup Karavel stable components repository: %w", err)
+	lockPath := filepath.Join(workdir, lockfile.Filename)
+	managedPaths := append(append([]string{}, cfg.Sources()...), lockPath, vendorDir, appsDir, projsDir, filepath.Join(workdir, "kustomization.yml"))
+	if params.Commit {
+		log.Debug("Checking git repository for unrelated changes")
+		// staging areas left behind by interrupted renders are removed when staging the new one
+		if err := gitutils.AssertClean(log, workdir, managedPaths, []string{filepath.Join(workdir, stagingPrefix)}); err != nil {
+			return err
+		}
 	}
 
-	log.Debugf("Updating Karavel components unstable repository %s", cfg.HelmUnstableRepoUrl)
-	ctx, err = addRepo(ctx, "unstable", cfg.HelmUnstableRepoUrl)
+	oldLock, err := lockfile.Read(lockPath)
 	if err != nil {
-		return fmt.Errorf("failed to setup Karavel stable components repository: %w", err)
+		return err
 	}
 
-	defer helmw.Clean(ctx)
-
 	log.Debug("Creating render plan from config")
-	p, err := plan.NewFromConfig(ctx, &cfg)
+	p, err := plan.NewFromConfig(ctx, cfg, params.Parallelism)
 	if err != nil {
 		return fmt.Errorf("failed to instantiate render plan from config: %w", err)
 	}
@@ -100,12 +207,68 @@ func Render(ctx context.Context, params RenderParams) error {
 		return err
 	}
 
+	var digests *postrender.PinDigests
+	if params.PinDigests {
+		digests = postrender.NewPinDigests(&oci.Resolver{Insecure: cfg.InsecureRegistries}, oldLock.Images)
+		p.PinDigests(digests)
+	}
+
 	argo := p.GetComponent("argocd")
 	if argo == nil {
 		argoEnabled = false
 		log.Warnf("ArgoCD component is missing. GitOps integrations will be disabled")
 	}
 
+	repoPath, repoUrl, repoRevision := "", "", ""
+	if !skipGit && argoEnabled {
+		res := argo.GetParam("git.repo")
+		log.Debug("Finding remote git repository URL to configure ArgoCD applications")
+		remote := params.GitRemote
+		if remote == "" {
+			remote = argo.GetParam("git.remote").String()
+		}
+		dir, url, err := gitutils.GetRemote(log, workdir, remote, res.String())
+		if err != nil {
+			return err
+		}
+
+		url, err = gitutils.ConvertRemoteUrl(url, argo.GetParam("git.scheme").String())
+		if err != nil {
+			return err
+		}
+
+		file, err := filepath.Rel(dir, workdir)
+		if err != nil {
+			return err
+		}
+
+		rev := argo.GetParam("git.revision").String()
+		if rev == "" {
+			log.Debug("Finding current git revision to configure ArgoCD applications")
+			rev, err = gitutils.GetCurrentRevision(log, workdir)
+			if err != nil {
+				return err
+			}
+		}
+
+		repoPath, repoUrl, repoRevision = file, url, rev
+	}
+
+	if stream {
+		return renderStream(ctx, p, argo, gitSource{path: repoPath, url: repoUrl, revision: repoRevision}, outfile, params.Stdout)
+	}
+
+	// files are rendered in a staging area and only replace the project ones if every step succeeds.
+	// vendor is fully managed and rendered from scratch, while changes to the other paths are preserved
+	stage, err := newStagingArea(log, workdir, renderedPaths, []string{"applications", "projects", "kustomization.yml"})
+	if err != nil {
+		return err
+	}
+	defer stage.discard()
+
+	liveVendorDir := vendorDir
+	vendorDir, appsDir, projsDir = stage.path("vendor"), stage.path("applications"), stage.path("projects")
+
 	assertDirs := []string{vendorDir}
 	if argoEnabled {
 		assertDirs = append(assertDirs, appsDir, projsDir)
@@ -118,125 +281,111 @@ func Render(ctx context.Context, params RenderParams) error {
 		}
 	}
 
-	var wg sync.WaitGroup
-	ch := make(chan utils.Pair[string, error])
-	appNames := make(chan string)
-	done := make(chan bool)
+	statePath := state.Path(workdir)
+	oldState, err := state.Read(statePath)
+	if err != nil {
+		log.Warnf("Ignoring unreadable state file, all components will be rendered: %s", err)
+		oldState = state.New()
+	}
+	newState := state.New()
+	skipped := 0
 
+	var mu sync.Mutex
 	var apps []string
 	var renderDirs []string
 	if argoEnabled {
 		renderDirs = []string{"applications", "projects"}
 	}
-	dirInfos, err := ioutil.ReadDir(vendorDir)
-	if err != nil {
-		return err
-	}
-
-	dirs := make(map[string]struct{}, len(dirInfos))
-	for _, i := range dirInfos {
-		dirs[i.Name()] = struct{}{}
-	}
-
-	repoPath, repoUrl := "", ""
-	if !skipGit && argoEnabled {
-		res := argo.GetParam("git.repo")
-		log.Debug("Finding remote git repository URL to configure ArgoCD applications")
-		dir, url, err := gitutils.GetOriginRemote(log, workdir, res.String())
-		if err != nil {
-			return err
-		}
-
-		file, err := filepath.Rel(dir, workdir)
-		if err != nil {
-			return err
+	for _, c := range p.Components() {
+		if c.IsBootstrap() {
+			renderDirs = append(renderDirs, filepath.Join("vendor", c.Name()))
 		}
-
-		repoPath, repoUrl = file, url
 	}
-
 	// empty line for nice logs
 	log.Info()
 
-	for _, c := range p.Components() {
-		if c.IsBootstrap() {
-			renderDirs = append(renderDirs, filepath.Join("vendor", c.Name()))
+	// ForEach waits for every component before returning, so that nothing is written to the staging area once it is discarded
+	err = utils.ForEach(ctx, params.Parallelism, p.Components(), func(ctx context.Context, _ int, comp *plan.Component) error {
+		msg := fmt.Sprintf("failed to render component '%s'", comp.Name())
+		outdir := filepath.Join(vendorDir, comp.Name())
+		liveDir := filepath.Join(liveVendorDir, comp.Name())
+		relDir := strings.ReplaceAll(liveDir, filepath.Dir(workdir)+"/", "")
+
+		inputs, err := comp.InputHash(ctx, cfg.Version)
+		if err != nil {
+			return fmt.Errorf("%s: %w", msg, err)
 		}
-		delete(dirs, c.Name())
 
-		wg.Add(1)
-		go func(comp *plan.Component) {
-			defer wg.Done()
+		fresh := false
+		if !params.Force {
+			fresh, err = oldState.Fresh(comp.Name(), inputs, liveDir)
+			if err != nil {
+				return fmt.Errorf("%s: %w", msg, err)
+			}
+		}
 
-			msg := fmt.Sprintf("failed to render component '%s'", comp.Name())
-			outdir := filepath.Join(vendorDir, comp.Name())
-			log.Infof("Rendering component %s at %s", comp.DebugLabel(), strings.ReplaceAll(outdir, filepath.Dir(workdir)+"/", ""))
-			log.Debugf("Component %s params: %s", comp.DebugLabel(), comp.Params())
+		if fresh {
+			log.Infof("Component %s at %s is up to date", comp.DebugLabel(), relDir)
+			if err := copyTree(liveDir, outdir); err != nil {
+				return fmt.Errorf("%s: %w", msg, err)
+			}
+		} else {
+			log.Infof("Rendering component %s at %s", comp.DebugLabel(), relDir)
+			log.Debugf("Component %s params: %s", comp.DebugLabel(), comp.RedactedParams())
 
 			if err := comp.Render(ctx, log, outdir); err != nil {
-				ch <- utils.NewPair(msg, err)
-				return
+				return fmt.Errorf("%s: %w", msg, err)
 			}
+		}
 
-			if argoEnabled {
-				log.Debugf("Rendering application manifest for component %s", comp.DebugLabel())
-				appFile := comp.Name() + ".yml"
-				appNames <- appFile
-				appFullPath := filepath.Join(appsDir, appFile)
-				// if the application file already exists, we skip it. It has already been created
-				// and we don't want to overwrite any changes the user may have made
-				_, err = os.Stat(appFullPath)
-				if !os.IsNotExist(err) {
-					ch <- utils.NewPair(msg, err)
-					return
-				}
-
-				argoNs := argo.Namespace()
-				vendorPath := path.Join(repoPath, "vendor", comp.Name())
-				if err := comp.RenderApplication(argoNs, repoUrl, vendorPath, appFullPath); err != nil {
-					ch <- utils.NewPair(msg, err)
-				}
-			}
-		}(c)
-	}
+		outputs, err := state.HashDir(outdir)
+		if err != nil {
+			return fmt.Errorf("%s: %w", msg, err)
+		}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
+		mu.Lock()
+		newState.Components[comp.Name()] = state.Component{Inputs: inputs, Outputs: outputs}
+		if fresh {
+			skipped++
+		}
+		mu.Unlock()
 
-		for dir := range dirs {
-			log.Debugf("deleting extraneous directory '%s' in vendor", dir)
-			if err := os.RemoveAll(filepath.Join(vendorDir, dir)); err != nil {
-				ch <- utils.NewPair(fmt.Sprintf("failed to delete extraneous directory '%s' in vendor", dir), err)
-			}
+		if !argoEnabled {
+			return nil
 		}
-	}()
-
-	go func() {
-		wg.Wait()
-		done <- true
-	}()
-
-	open := true
-	for open {
-		select {
-		case name := <-appNames:
-			apps = append(apps, name)
-		case pair := <-ch:
-			err := pair.B()
+
+		log.Debugf("Rendering application manifest for component %s", comp.DebugLabel())
+		appFile := comp.Name() + ".yml"
+		mu.Lock()
+		apps = append(apps, appFile)
+		mu.Unlock()
+
+		appFullPath := filepath.Join(appsDir, appFile)
+		// if the application file already exists, we skip it. It has already been created
+		// and we don't want to overwrite any changes the user may have made
+		if _, err := os.Stat(appFullPath); !os.IsNotExist(err) {
 			if err != nil {
-				return fmt.Errorf("%s: %w", pair.A(), err)
+				return fmt.Errorf("%s: %w", msg, err)
 			}
-		case <-done:
-			open = false
+			return nil
+		}
+
+		argoNs := argo.Namespace()
+		vendorPath := path.Join(repoPath, "vendor", comp.Name())
+		if err := comp.RenderApplication(argoNs, repoUrl, repoRevision, vendorPath, appFullPath); err != nil {
+			return fmt.Errorf("%s: %w", msg, err)
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	if argoEnabled {
 		argoNs := argo.Namespace()
 		apps = append(apps, "projects.yml", "bootstrap.yml")
 		sort.Strings(apps)
-		if err := utils.RenderKustomizeFile(appsDir, apps, predicate.IsStringInSlice(apps)); err != nil {
+		if err := utils.RenderKustomizeFile(appsDir, apps, predicate.IsStringInSlice(apps), nil); err != nil {
 			return fmt.Errorf("failed to render applications kustomization.yml: %w", err)
 		}
 
@@ -246,14 +395,14 @@ func Render(ctx context.Context, params RenderParams) error {
 		}
 
 		projs := []string{infraProj}
-		if err := utils.RenderKustomizeFile(projsDir, projs, predicate.IsStringInSlice(projs)); err != nil {
+		if err := utils.RenderKustomizeFile(projsDir, projs, predicate.IsStringInSlice(projs), nil); err != nil {
 			return fmt.Errorf("failed to render projects kustomization.yml: %w", err)
 		}
 
 		projsAppPath := filepath.Join(appsDir, "projects.yml")
 		_, err = os.Stat(projsAppPath)
 		if os.IsNotExist(err) {
-			projsApp := argocd.NewApplication("projects", "argocd", "argocd", repoUrl, path.Join(repoPath, "projects"))
+			projsApp := argocd.NewApplication("projects", "argocd", "argocd", repoUrl, repoRevision, path.Join(repoPath, "projects"))
 			if err := projsApp.Render(projsAppPath); err != nil {
 				return fmt.Errorf("failed to render projects application: %w", err)
 			}
@@ -262,7 +411,7 @@ func Render(ctx context.Context, params RenderParams) error {
 		bootstrapAppPath := filepath.Join(appsDir, "bootstrap.yml")
 		_, err = os.Stat(bootstrapAppPath)
 		if os.IsNotExist(err) {
-			bootstrap := argocd.NewApplication("bootstrap", "argocd", "argocd", repoUrl, path.Join(repoPath, "applications"))
+			bootstrap := argocd.NewApplication("bootstrap", "argocd", "argocd", repoUrl, repoRevision, path.Join(repoPath, "applications"))
 			if err := bootstrap.Render(bootstrapAppPath); err != nil {
 				return fmt.Errorf("failed to render bootstrap application: %w", err)
 			}
@@ -270,13 +419,83 @@ func Render(ctx context.Context, params RenderParams) error {
 
 	}
 
-	if err := utils.RenderKustomizeFile(workdir, renderDirs, predicate.StringOr(predicate.IsStringInSlice(renderDirs), predicate.StringHasPrefix("vendor"))); err != nil {
+	if err := utils.RenderKustomizeFile(stage.dir, renderDirs, predicate.StringOr(predicate.IsStringInSlice(renderDirs), predicate.StringHasPrefix("vendor")), nil); err != nil {
 		return fmt.Errorf("failed to render kustomization.yml: %w", err)
 	}
 
+	lock := lockfile.New(cfg.Version)
+	for _, c := range p.Components() {
+		lock.Components[c.Name()] = lockfile.Component{
+			Component: c.ComponentName(),
+			Version:   c.Version(),
+			Unstable:  c.IsUnstable(),
+		}
+	}
+
+	if digests != nil {
+		lock.Images = digests.Resolved()
+		// the images of the skipped components were not resolved again, keep their digests
+		if skipped > 0 {
+			for image, d := range oldLock.Images {
+				if _, ok := lock.Images[image]; !ok {
+					lock.Images[image] = d
+				}
+			}
+		}
+	}
+
+	if err := lock.Write(stage.path(lockfile.Filename)); err != nil {
+		return fmt.Errorf("failed to write %s: %w", lockfile.Filename, err)
+	}
+
+	log.Debug("Replacing project files with the rendered ones")
+	if err := stage.commit(); err != nil {
+		return err
+	}
+
+	// the state only speeds up the next render, failing to record it is not fatal
+	if err := newState.Write(statePath); err != nil {
+		log.Warnf("Failed to write state file %s: %s", statePath, err)
+	}
+
+	if params.Commit {
+		log.Info()
+		log.Debug("Committing rendered files")
+		hash, err := gitutils.CommitPaths(log, workdir, managedPaths, commitMessage(lock, oldLock))
+		if err != nil {
+			return err
+		}
+
+		if hash == "" {
+			log.Info("Nothing to commit, the rendered files are already up to date")
+		} else {
+			log.Infof("Committed rendered files as %s", hash)
+		}
+	}
+
 	return nil
 }
 
+func commitMessage(lock lockfile.Lockfile, old lockfile.Lockfile) string {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "Render Karavel Container Platform %s\n", lock.Version)
+
+	changes := lock.Diff(old)
+	upgraded := old.Version != "" && old.Version != lock.Version
+	if len(changes) == 0 && !upgraded {
+		return b.String()
+	}
+
+	b.WriteString("\n")
+	if upgraded {
+		_, _ = fmt.Fprintf(&b, "Platform: %s -> %s\n", old.Version, lock.Version)
+	}
+	for _, c := range changes {
+		_, _ = fmt.Fprintf(&b, "- %s\n", c)
+	}
+	return b.String()
+}
+
 const argoProject = `
 apiVersion: argoproj.io/v1alpha1
 kind: AppProject
@@ -295,3 +514,19 @@ spec:
       kind: '*'
 
 `
+
+// checkCliVersion refuses to proceed if the running CLI does not satisfy the constraint declared by source
+func checkCliVersion(log logger.Logger, constraint string, source string) error {
+	err := version.CheckConstraint(constraint)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, version.ErrUnknownVersion):
+		log.Warnf("Skipping CLI version check required by %s: %s", source, err)
+		return nil
+	case errors.Is(err, version.ErrIncompatible):
+		return fmt.Errorf("%w: %s requires CLI version '%s', but this is %s. Run 'karavel self-update' to update it", version.ErrIncompatible, source, constraint, version.Get().Version)
+	default:
+		return fmt.Errorf("failed to check CLI version required by %s: %w", source, err)
+	}
+}
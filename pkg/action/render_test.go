@@ -0,0 +1,207 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/repo"
+
+	"github.com/karavel-io/cli/pkg/logger"
+)
+
+const testDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}-web
+spec:
+  replicas: {{ .Values.replicas }}
+  template:
+    spec:
+      containers:
+        - name: web
+          image: docker.io/library/nginx:1.23
+`
+
+const testClusterRole = `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: web
+rules: []
+`
+
+// newTestRepository serves a components repository with a single "web" chart, returning its URL
+func newTestRepository(t *testing.T) string {
+	dir := t.TempDir()
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "web", Version: "0.1.0"},
+		Values:   map[string]any{"namespace": "web", "replicas": 1},
+		Raw:      []*chart.File{{Name: chartutil.ValuesfileName, Data: []byte("namespace: web\nreplicas: 1\n")}},
+		Templates: []*chart.File{
+			{Name: "templates/deployment.yaml", Data: []byte(testDeployment)},
+			{Name: "templates/clusterrole.yaml", Data: []byte(testClusterRole)},
+		},
+	}
+	_, err := chartutil.Save(ch, dir)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	t.Cleanup(srv.Close)
+
+	idx, err := repo.IndexDirectory(dir, srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, idx.WriteFile(filepath.Join(dir, "index.yaml"), 0o644))
+
+	return srv.URL
+}
+
+// newTestProject creates a git repository holding a committed config file rendering the test repository charts
+func newTestProject(t *testing.T, repoUrl string) string {
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	cfg, err := r.Config()
+	require.NoError(t, err)
+	cfg.User.Name = "Karavel"
+	cfg.User.Email = "karavel@example.com"
+	require.NoError(t, r.SetConfig(cfg))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "karavel.hcl"), []byte(`
+version       = "1970.1"
+stable_repo   = "`+repoUrl+`"
+unstable_repo = "`+repoUrl+`"
+
+component "web" {
+  replicas = 2
+}
+`), 0o644))
+
+	wt, err := r.Worktree()
+	require.NoError(t, err)
+	_, err = wt.Add("karavel.hcl")
+	require.NoError(t, err)
+	_, err = wt.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	return dir
+}
+
+// appendConfig appends content to the config file at cpath
+func appendConfig(t *testing.T, cpath string, content string) {
+	f, err := os.OpenFile(cpath, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func testContext() context.Context {
+	return logger.WithLogger(context.Background(), logger.New(logger.LvlError))
+}
+
+func TestRenderCommitAfterRenders(t *testing.T) {
+	dir := newTestProject(t, newTestRepository(t))
+	params := RenderParams{ConfigPath: filepath.Join(dir, "karavel.hcl"), Parallelism: 1}
+
+	require.NoError(t, Render(testContext(), params))
+	require.NoError(t, Render(testContext(), params))
+
+	params.Commit = true
+	require.NoError(t, Render(testContext(), params))
+
+	r, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	wt, err := r.Worktree()
+	require.NoError(t, err)
+	st, err := wt.Status()
+	require.NoError(t, err)
+	require.True(t, st.IsClean(), st.String())
+}
+
+func TestRenderCommitAfterInterruptedRender(t *testing.T) {
+	dir := newTestProject(t, newTestRepository(t))
+
+	newStaging := func(name string) string {
+		d := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Join(d, "vendor"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(d, "vendor", "kustomization.yml"), []byte("resources: []"), 0o644))
+		return d
+	}
+
+	// a staging area left behind by a render that was killed
+	stale := newStaging(stagingPrefix + "1234")
+	old := time.Now().Add(-2 * staleStagingAge)
+	for _, p := range []string{filepath.Join(stale, "vendor", "kustomization.yml"), filepath.Join(stale, "vendor"), stale} {
+		require.NoError(t, os.Chtimes(p, old, old))
+	}
+	// and one of a render still in progress
+	running := newStaging(stagingPrefix + "5678")
+
+	require.NoError(t, Render(testContext(), RenderParams{ConfigPath: filepath.Join(dir, "karavel.hcl"), Commit: true}))
+	assert.NoDirExists(t, stale)
+	assert.DirExists(t, running)
+}
+
+func TestRenderExecPostRendererChange(t *testing.T) {
+	repoUrl := newTestRepository(t)
+	dir := newTestProject(t, repoUrl)
+	cpath := filepath.Join(dir, "karavel.hcl")
+	require.NoError(t, os.WriteFile(cpath, []byte(`
+version       = "1970.1"
+stable_repo   = "`+repoUrl+`"
+unstable_repo = "`+repoUrl+`"
+
+component "web" {
+  post_renderer "exec" {
+    command = "./scale.sh"
+  }
+}
+`), 0o644))
+
+	script := filepath.Join(dir, "scale.sh")
+	writeScript := func(replicas string) {
+		require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\nsed 's/replicas: 1/replicas: "+replicas+"/'\n"), 0o755))
+	}
+	deployment := filepath.Join(dir, "vendor", "web", "deployment-dummy-web.yml")
+	params := RenderParams{ConfigPath: cpath, SkipGit: true, Parallelism: 1}
+
+	writeScript("3")
+	require.NoError(t, Render(testContext(), params))
+	b, err := os.ReadFile(deployment)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "replicas: 3")
+
+	// editing the script must render the component again
+	writeScript("4")
+	require.NoError(t, Render(testContext(), params))
+	b, err = os.ReadFile(deployment)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "replicas: 4")
+}
@@ -0,0 +1,153 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/karavel-io/cli/internal/selfupdate"
+	"github.com/karavel-io/cli/internal/utils"
+	"github.com/karavel-io/cli/internal/version"
+	"github.com/karavel-io/cli/pkg/logger"
+)
+
+type SelfUpdateParams struct {
+	// Version to install, defaults to the latest release
+	Version string
+	// CheckOnly reports whether an update is available without installing it
+	CheckOnly bool
+	// Force installs the release even if it is not newer than the running CLI
+	Force bool
+	// GitHubRepo overrides the repository publishing the CLI releases
+	GitHubRepo string
+}
+
+func SelfUpdate(ctx context.Context, params SelfUpdateParams) error {
+	log := logger.FromContext(ctx)
+	src := selfupdate.Source{Repo: params.GitHubRepo, Token: os.Getenv("GITHUB_TOKEN")}
+
+	var rel selfupdate.Release
+	var err error
+	if params.Version == "" || params.Version == "latest" {
+		log.Debug("Fetching latest CLI release")
+		rel, err = src.Latest(ctx)
+	} else {
+		log.Debugf("Fetching CLI release %s", params.Version)
+		rel, err = src.Get(ctx, params.Version)
+	}
+	if err != nil {
+		return err
+	}
+
+	current := version.Get().Version
+	newer, err := isNewer(rel.Version, current)
+	if err != nil {
+		return err
+	}
+
+	if !newer && !params.Force {
+		log.Infof("Karavel CLI %s is up to date", current)
+		return nil
+	}
+
+	if params.CheckOnly {
+		log.Infof("Karavel CLI %s is available, the running version is %s", rel.Version, current)
+		return nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the running executable: %w", err)
+	}
+	exe, err = filepath.EvalSymlinks(exe)
+	if err != nil {
+		return fmt.Errorf("failed to locate the running executable: %w", err)
+	}
+
+	archive := selfupdate.ArchiveName(rel.Version, runtime.GOOS, runtime.GOARCH)
+	if _, ok := rel.Assets[archive]; !ok {
+		return fmt.Errorf("Karavel CLI %s has no release archive for %s/%s", rel.Version, runtime.GOOS, runtime.GOARCH)
+	}
+	if _, ok := rel.Assets[selfupdate.ChecksumsFile]; !ok {
+		return fmt.Errorf("Karavel CLI %s has no %s, refusing to install an unverified binary", rel.Version, selfupdate.ChecksumsFile)
+	}
+
+	tmpdir, err := os.MkdirTemp("", "karavel-self-update-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpdir)
+
+	files := []string{archive, selfupdate.ChecksumsFile}
+	sig := selfupdate.ChecksumsFile + selfupdate.SignatureExt
+	_, signed := rel.Assets[sig]
+	if signed {
+		files = append(files, sig)
+	}
+
+	for _, f := range files {
+		if err := utils.DownloadWithProgress(ctx, log, rel.Assets[f], filepath.Join(tmpdir, f)); err != nil {
+			return fmt.Errorf("failed to download %s: %w", f, err)
+		}
+	}
+
+	checksums := filepath.Join(tmpdir, selfupdate.ChecksumsFile)
+	if signed {
+		log.Debugf("Verifying signature of %s", selfupdate.ChecksumsFile)
+		if err := selfupdate.VerifySignature(checksums, filepath.Join(tmpdir, sig)); err != nil {
+			return err
+		}
+	}
+
+	sums, err := ioutil.ReadFile(checksums)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Verifying checksum of %s", archive)
+	if err := selfupdate.VerifyChecksum(sums, archive, filepath.Join(tmpdir, archive)); err != nil {
+		return err
+	}
+
+	log.Infof("Installing Karavel CLI %s to %s", rel.Version, exe)
+	if err := selfupdate.Install(filepath.Join(tmpdir, archive), selfupdate.BinaryName(runtime.GOOS), exe); err != nil {
+		return fmt.Errorf("failed to install Karavel CLI %s: %w", rel.Version, err)
+	}
+
+	log.Infof("Karavel CLI updated from %s to %s", current, rel.Version)
+	return nil
+}
+
+// isNewer reports whether candidate is newer than current. Development builds are never up to date.
+func isNewer(candidate string, current string) (bool, error) {
+	cand, err := semver.NewVersion(candidate)
+	if err != nil {
+		return false, fmt.Errorf("invalid release version %s: %w", candidate, err)
+	}
+
+	cur, err := semver.NewVersion(current)
+	if err != nil {
+		return true, nil
+	}
+
+	return cand.GreaterThan(cur), nil
+}
@@ -0,0 +1,217 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/karavel-io/cli/pkg/logger"
+)
+
+// stagingPrefix names the temporary directories holding a render in progress
+const stagingPrefix = ".karavel-render-"
+
+// stagingArea holds a render in progress, so that the project files are only replaced once every step succeeded.
+// Paths are relative to the project directory.
+type stagingArea struct {
+	log     logger.Logger
+	workdir string
+	dir     string
+	paths   []string
+}
+
+// newStagingArea creates a staging directory inside workdir, so that it can be swapped in with a rename.
+// The preserved paths are copied over, as the render keeps the changes made to them.
+func newStagingArea(log logger.Logger, workdir string, paths []string, preserved []string) (*stagingArea, error) {
+	removeStaleStagingAreas(log, workdir)
+
+	dir, err := ioutil.TempDir(workdir, stagingPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	s := &stagingArea{log: log, workdir: workdir, dir: dir, paths: paths}
+	for _, p := range preserved {
+		if err := copyTree(filepath.Join(workdir, p), filepath.Join(dir, p)); err != nil {
+			s.discard()
+			return nil, fmt.Errorf("failed to stage %s: %w", p, err)
+		}
+	}
+	return s, nil
+}
+
+// staleStagingAge is how long a staging directory must go unmodified before it is considered abandoned.
+// Renders running concurrently in the same project, like watch next to a manual render, keep theirs fresh.
+const staleStagingAge = time.Hour
+
+// removeStaleStagingAreas removes the staging directories left behind by renders that were killed before cleaning up
+func removeStaleStagingAreas(log logger.Logger, workdir string) {
+	dirs, err := filepath.Glob(filepath.Join(workdir, stagingPrefix+"*"))
+	if err != nil {
+		return
+	}
+
+	for _, dir := range dirs {
+		if time.Since(lastModified(dir)) < staleStagingAge {
+			log.Debugf("Keeping staging directory %s, another render may be using it", dir)
+			continue
+		}
+
+		log.Debugf("Removing stale staging directory %s", dir)
+		if err := os.RemoveAll(dir); err != nil {
+			log.Warnf("Failed to remove stale staging directory %s: %s", dir, err)
+		}
+	}
+}
+
+// lastModified returns the latest modification time of dir and its content
+func lastModified(dir string) time.Time {
+	var latest time.Time
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	return latest
+}
+
+// path returns the staged location of a project path
+func (s *stagingArea) path(rel string) string {
+	return filepath.Join(s.dir, rel)
+}
+
+// commit moves the staged paths into the project. If a move fails, the paths already replaced are restored.
+func (s *stagingArea) commit() error {
+	backup := filepath.Join(s.dir, ".backup")
+	if err := os.Mkdir(backup, 0o755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	type swap struct {
+		live   string
+		backup string
+	}
+	var done []swap
+	rollback := func() {
+		for i := len(done) - 1; i >= 0; i-- {
+			sw := done[i]
+			if err := os.RemoveAll(sw.live); err != nil {
+				s.log.Errorf("Failed to roll back %s: %s", sw.live, err)
+				continue
+			}
+			if sw.backup == "" {
+				continue
+			}
+			if err := os.Rename(sw.backup, sw.live); err != nil {
+				s.log.Errorf("Failed to restore %s from %s: %s", sw.live, sw.backup, err)
+			}
+		}
+	}
+
+	for _, p := range s.paths {
+		staged := s.path(p)
+		if _, err := os.Lstat(staged); os.IsNotExist(err) {
+			continue
+		}
+
+		sw := swap{live: filepath.Join(s.workdir, p)}
+		if _, err := os.Lstat(sw.live); err == nil {
+			sw.backup = filepath.Join(backup, p)
+			if err := os.Rename(sw.live, sw.backup); err != nil {
+				rollback()
+				return fmt.Errorf("failed to replace %s: %w", p, err)
+			}
+		}
+
+		if err := os.Rename(staged, sw.live); err != nil {
+			if sw.backup != "" {
+				if rerr := os.Rename(sw.backup, sw.live); rerr != nil {
+					s.log.Errorf("Failed to restore %s from %s: %s", sw.live, sw.backup, rerr)
+				}
+			}
+			rollback()
+			return fmt.Errorf("failed to replace %s: %w", p, err)
+		}
+		done = append(done, sw)
+	}
+
+	return nil
+}
+
+// discard removes the staging directory, along with any backup left by commit
+func (s *stagingArea) discard() {
+	if err := os.RemoveAll(s.dir); err != nil {
+		s.log.Warnf("Could not remove staging directory %s: %s", s.dir, err)
+	}
+}
+
+// copyTree copies a file or directory, preserving file modes. Missing sources are ignored.
+func copyTree(src string, dst string) error {
+	if _, err := os.Lstat(src); os.IsNotExist(err) {
+		return nil
+	}
+
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+
+		in, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(out, in); err != nil {
+			_ = out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
@@ -0,0 +1,88 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/karavel-io/cli/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStagingArea(t *testing.T) {
+	log := logger.New(logger.LvlError)
+	workdir := t.TempDir()
+	write := func(p string, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+	read := func(p string) string {
+		b, err := os.ReadFile(p)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	write(filepath.Join(workdir, "vendor", "old.yml"), "old")
+	write(filepath.Join(workdir, "applications", "custom.yml"), "custom")
+	write(filepath.Join(workdir, "karavel.lock"), "old lock")
+
+	paths := []string{"vendor", "applications", "karavel.lock"}
+	stage, err := newStagingArea(log, workdir, paths, []string{"applications", "projects"})
+	require.NoError(t, err)
+
+	// preserved paths are copied, missing ones are skipped
+	assert.Equal(t, "custom", read(stage.path("applications/custom.yml")))
+	assert.NoDirExists(t, stage.path("projects"))
+
+	write(stage.path("vendor/new.yml"), "new")
+	write(stage.path("karavel.lock"), "new lock")
+
+	// nothing is touched until the commit
+	assert.FileExists(t, filepath.Join(workdir, "vendor", "old.yml"))
+	assert.NoFileExists(t, filepath.Join(workdir, "vendor", "new.yml"))
+
+	require.NoError(t, stage.commit())
+	stage.discard()
+
+	assert.NoFileExists(t, filepath.Join(workdir, "vendor", "old.yml"))
+	assert.Equal(t, "new", read(filepath.Join(workdir, "vendor", "new.yml")))
+	assert.Equal(t, "custom", read(filepath.Join(workdir, "applications", "custom.yml")))
+	assert.Equal(t, "new lock", read(filepath.Join(workdir, "karavel.lock")))
+
+	entries, err := os.ReadDir(workdir)
+	require.NoError(t, err)
+	for _, e := range entries {
+		assert.NotContains(t, e.Name(), stagingPrefix)
+	}
+}
+
+func TestStagingAreaDiscard(t *testing.T) {
+	log := logger.New(logger.LvlError)
+	workdir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workdir, "karavel.lock"), []byte("old lock"), 0o644))
+
+	stage, err := newStagingArea(log, workdir, []string{"karavel.lock"}, nil)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(stage.path("karavel.lock"), []byte("new lock"), 0o644))
+	stage.discard()
+
+	b, err := os.ReadFile(filepath.Join(workdir, "karavel.lock"))
+	require.NoError(t, err)
+	assert.Equal(t, "old lock", string(b))
+	assert.NoDirExists(t, stage.dir)
+}
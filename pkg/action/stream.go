@@ -0,0 +1,124 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	argocd "github.com/karavel-io/cli/internal/argo"
+	"github.com/karavel-io/cli/internal/helmw"
+	"github.com/karavel-io/cli/internal/plan"
+	"github.com/karavel-io/cli/pkg/logger"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	OutputDir        = "dir"
+	OutputStdout     = "stdout"
+	OutputFilePrefix = "file:"
+)
+
+// gitSource is the git location ArgoCD applications sync from
+type gitSource struct {
+	path     string
+	url      string
+	revision string
+}
+
+// parseOutput returns whether the output is a single YAML stream, and the file to write it to.
+// The file is empty for the stdout output.
+func parseOutput(output string) (string, bool, error) {
+	switch {
+	case output == "" || output == OutputDir:
+		return "", false, nil
+	case output == OutputStdout:
+		return "", true, nil
+	case strings.HasPrefix(output, OutputFilePrefix):
+		file := strings.TrimPrefix(output, OutputFilePrefix)
+		if file == "" {
+			return "", false, fmt.Errorf("output '%s' is missing the file path", output)
+		}
+		return file, true, nil
+	default:
+		return "", false, fmt.Errorf("unsupported output '%s', must be one of %s, %s or %s<path>", output, OutputDir, OutputStdout, OutputFilePrefix)
+	}
+}
+
+// renderStream writes the documents of all the components in dependency order as a single YAML stream,
+// followed by the ArgoCD project and applications. Nothing is written until every component is rendered.
+func renderStream(ctx context.Context, p *plan.Plan, argo *plan.Component, git gitSource, outfile string, stdout io.Writer) error {
+	log := logger.FromContext(ctx)
+
+	comps := p.SortedComponents()
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, c := range comps {
+		log.Infof("Rendering component %s", c.DebugLabel())
+		log.Debugf("Component %s params: %s", c.DebugLabel(), c.RedactedParams())
+
+		docs, err := c.Manifests(ctx, log)
+		if err != nil {
+			return fmt.Errorf("failed to render component '%s': %w", c.Name(), err)
+		}
+
+		for _, doc := range docs {
+			if len(doc) == 0 {
+				continue
+			}
+			if err := enc.Encode(doc); err != nil {
+				return fmt.Errorf("failed to encode component '%s': %w", c.Name(), err)
+			}
+		}
+	}
+
+	if argo != nil {
+		argoNs := argo.Namespace()
+		var proj helmw.YamlDoc
+		if err := yaml.Unmarshal([]byte(fmt.Sprintf(argoProject, argoNs)), &proj); err != nil {
+			return fmt.Errorf("failed to render infrastructure project: %w", err)
+		}
+		if err := enc.Encode(proj); err != nil {
+			return fmt.Errorf("failed to render infrastructure project: %w", err)
+		}
+
+		for _, c := range comps {
+			app := argocd.NewApplication(c.Name(), c.Namespace(), argoNs, git.url, git.revision, path.Join(git.path, "vendor", c.Name()))
+			if err := enc.Encode(&app); err != nil {
+				return fmt.Errorf("failed to render application manifest '%s': %w", c.Name(), err)
+			}
+		}
+	}
+
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	if outfile == "" {
+		_, err := stdout.Write(buf.Bytes())
+		return err
+	}
+
+	log.Infof("Writing rendered documents to %s", outfile)
+	return ioutil.WriteFile(outfile, buf.Bytes(), 0o644)
+}
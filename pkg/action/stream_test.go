@@ -0,0 +1,76 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/karavel-io/cli/internal/helmw"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseOutput(t *testing.T) {
+	for output, want := range map[string]struct {
+		file   string
+		stream bool
+	}{
+		"":                     {},
+		"dir":                  {},
+		"stdout":               {stream: true},
+		"file:out/karavel.yml": {file: "out/karavel.yml", stream: true},
+	} {
+		file, stream, err := parseOutput(output)
+		assert.NoError(t, err, output)
+		assert.Equal(t, want.file, file, output)
+		assert.Equal(t, want.stream, stream, output)
+	}
+
+	_, _, err := parseOutput("file:")
+	assert.ErrorContains(t, err, "missing the file path")
+
+	_, _, err = parseOutput("s3://bucket")
+	assert.ErrorContains(t, err, "unsupported output 's3://bucket'")
+}
+
+func TestRenderStream(t *testing.T) {
+	dir := newTestProject(t, newTestRepository(t))
+
+	var out bytes.Buffer
+	require.NoError(t, Render(testContext(), RenderParams{
+		ConfigPath: filepath.Join(dir, "karavel.hcl"),
+		Output:     OutputStdout,
+		Stdout:     &out,
+	}))
+
+	namespaces := make(map[string]any)
+	dec := yaml.NewDecoder(strings.NewReader(out.String()))
+	for {
+		var doc helmw.YamlDoc
+		if err := dec.Decode(&doc); err != nil {
+			break
+		}
+		md := doc["metadata"].(helmw.YamlDoc)
+		namespaces[doc["kind"].(string)] = md["namespace"]
+	}
+
+	// namespaced documents are assigned the component namespace, as there is no ArgoCD application to do it
+	assert.Equal(t, map[string]any{"Deployment": "web", "ClusterRole": nil}, namespaces)
+}
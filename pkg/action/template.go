@@ -0,0 +1,163 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/go-git/go-git/v5"
+
+	"github.com/karavel-io/cli/pkg/logger"
+)
+
+// templateSuffix marks starter kit files that are rendered as Go templates
+const templateSuffix = ".tmpl"
+
+type templateData struct {
+	Version string
+}
+
+func isGitUrl(src string) bool {
+	return strings.Contains(src, "://") || strings.HasPrefix(src, "git@") || strings.HasSuffix(src, ".git")
+}
+
+// fetchTemplate returns the local directory containing the starter kit, cloning it if src is a git URL.
+// The returned cleanup function must always be called.
+func fetchTemplate(ctx context.Context, src string) (string, func(), error) {
+	noop := func() {}
+	if !isGitUrl(src) {
+		info, err := os.Stat(src)
+		if err != nil {
+			return "", noop, fmt.Errorf("failed to read template %s: %w", src, err)
+		}
+
+		if !info.IsDir() {
+			return "", noop, fmt.Errorf("invalid template %s, is not a directory", src)
+		}
+		return src, noop, nil
+	}
+
+	dir, err := os.MkdirTemp("", "karavel-template-")
+	if err != nil {
+		return "", noop, err
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+
+	logger.FromContext(ctx).Infof("Cloning template repository %s", src)
+	_, err = git.PlainCloneContext(ctx, dir, false, &git.CloneOptions{
+		URL:   src,
+		Depth: 1,
+	})
+	if err != nil {
+		cleanup()
+		return "", noop, fmt.Errorf("failed to clone template repository %s: %w", src, err)
+	}
+
+	return dir, cleanup, nil
+}
+
+// templateFile is a starter kit file rendered in memory, waiting to be written
+type templateFile struct {
+	rel     string
+	content []byte
+	mode    fs.FileMode
+}
+
+// copyTemplate copies the starter kit in src to dst, rendering template files with the given data.
+// Every file is rendered and checked for conflicts before anything is written, so that a failure leaves dst untouched.
+func copyTemplate(log logger.Logger, src string, dst string, data templateData, force bool) error {
+	var dirs []string
+	var files []templateFile
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if d.Name() == git.GitDirName {
+				return filepath.SkipDir
+			}
+			dirs = append(dirs, rel)
+			return nil
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasSuffix(rel, templateSuffix) {
+			rel = strings.TrimSuffix(rel, templateSuffix)
+			tpl, err := template.New(rel).Parse(string(content))
+			if err != nil {
+				return fmt.Errorf("failed to parse template file %s: %w", rel, err)
+			}
+
+			var buf bytes.Buffer
+			if err := tpl.Execute(&buf, data); err != nil {
+				return fmt.Errorf("failed to render template file %s: %w", rel, err)
+			}
+			content = buf.Bytes()
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		files = append(files, templateFile{rel: rel, content: content, mode: info.Mode().Perm()})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if _, err := os.Stat(filepath.Join(dst, f.rel)); err == nil {
+			if !force {
+				return fmt.Errorf("file %s already exists", f.rel)
+			}
+			log.Warnf("File %s already exists and will be overwritten", f.rel)
+		}
+	}
+
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(dst, dir), 0o755); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range files {
+		filedst := filepath.Join(dst, f.rel)
+		log.Debugf("Writing file %s", filedst)
+		if err := ioutil.WriteFile(filedst, f.content, f.mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/karavel-io/cli/internal/helmw"
+	"github.com/karavel-io/cli/pkg/config"
+	"github.com/karavel-io/cli/pkg/logger"
+
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+type UpgradeParams struct {
+	ConfigPath     string
+	KaravelVersion string
+	ReleaseSource  string
+	Render         bool
+	// RenderParams are the options of the render following the upgrade. The config path is the upgraded one
+	RenderParams RenderParams
+}
+
+type componentUpgrade struct {
+	name string
+	from string
+	to   string
+}
+
+func Upgrade(ctx context.Context, params UpgradeParams) error {
+	cpath := params.ConfigPath
+	ver := params.KaravelVersion
+
+	log := logger.FromContext(ctx)
+
+	log.Debug("Reading config file")
+	cfg, err := config.ReadFrom(log.Writer(), cpath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if ver == "" || ver == "latest" {
+		ver, err = fetchLatestVersion(ctx, params.ReleaseSource)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ver == cfg.Version {
+		log.Infof("Karavel project is already at version %s", ver)
+	} else {
+		log.Infof("Upgrading Karavel project from version %s to %s", cfg.Version, ver)
+		log.Info()
+
+		newRepoUrl := cfg.HelmStableRepoUrl
+		if newRepoUrl == helmw.GetRepoUrl(cfg.Version, "") {
+			newRepoUrl = helmw.GetRepoUrl(ver, "")
+		} else {
+			log.Warnf("Custom stable repository %s is configured, its components will not change", newRepoUrl)
+		}
+
+		oldIdx, err := helmw.LoadIndex(ctx, cfg.HelmStableRepoUrl)
+		if err != nil {
+			return fmt.Errorf("failed to load Karavel %s components repository: %w", cfg.Version, err)
+		}
+
+		newIdx, err := helmw.LoadIndex(ctx, newRepoUrl)
+		if err != nil {
+			return fmt.Errorf("failed to load Karavel %s components repository: %w", ver, err)
+		}
+
+		unstableIdx, err := helmw.LoadIndex(ctx, cfg.HelmUnstableRepoUrl)
+		if err != nil {
+			return fmt.Errorf("failed to load Karavel unstable components repository: %w", err)
+		}
+
+		upgrades, warnings := planUpgrade(cfg.Components, ver, oldIdx, newIdx, unstableIdx)
+		for _, w := range warnings {
+			log.Warn(w)
+		}
+
+		if len(upgrades) == 0 {
+			log.Info("No component version changes")
+		}
+		for _, u := range upgrades {
+			log.Infof("Component %s: %s -> %s", u.name, u.from, u.to)
+		}
+
+		log.Info()
+		log.Infof("Updating version in config file %s", cfg.MainFile())
+		if err := config.SetVersion(cfg.MainFile(), ver); err != nil {
+			return err
+		}
+	}
+
+	if params.Render {
+		log.Info()
+		rp := params.RenderParams
+		rp.ConfigPath = cpath
+		return Render(ctx, rp)
+	}
+
+	return nil
+}
+
+// planUpgrade lists the components whose chart version changes between the old and new repository indexes,
+// together with warnings about components that are no longer available in the new stable repository
+func planUpgrade(components []config.Component, ver string, oldIdx *repo.IndexFile, newIdx *repo.IndexFile, unstableIdx *repo.IndexFile) ([]componentUpgrade, []string) {
+	var upgrades []componentUpgrade
+	var warnings []string
+	for _, cc := range components {
+		if cc.Unstable {
+			// unstable components are not tied to a platform release
+			continue
+		}
+
+		chartName := cc.Name
+		if cc.ComponentName != "" {
+			chartName = cc.ComponentName
+		}
+
+		from := ""
+		if cv, err := oldIdx.Get(chartName, cc.Version); err == nil {
+			from = cv.Version
+		}
+
+		cv, err := newIdx.Get(chartName, cc.Version)
+		if err != nil {
+			switch {
+			case cc.Version != "" && len(newIdx.Entries[chartName]) > 0:
+				warnings = append(warnings, fmt.Sprintf("Component '%s' is pinned to version %s, which is not available in Karavel %s", cc.Name, cc.Version, ver))
+			case len(unstableIdx.Entries[chartName]) > 0:
+				warnings = append(warnings, fmt.Sprintf("Component '%s' is only available in the unstable repository in Karavel %s", cc.Name, ver))
+			default:
+				warnings = append(warnings, fmt.Sprintf("Component '%s' is no longer available in Karavel %s", cc.Name, ver))
+			}
+			continue
+		}
+
+		if cv.Version != from {
+			if from == "" {
+				from = "(none)"
+			}
+			upgrades = append(upgrades, componentUpgrade{name: cc.Name, from: from, to: cv.Version})
+		}
+	}
+
+	return upgrades, warnings
+}
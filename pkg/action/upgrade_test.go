@@ -0,0 +1,100 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/karavel-io/cli/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+func newIndex(t *testing.T, charts map[string][]string) *repo.IndexFile {
+	idx := repo.NewIndexFile()
+	for name, versions := range charts {
+		for _, v := range versions {
+			md := &chart.Metadata{APIVersion: chart.APIVersionV2, Name: name, Version: v}
+			require.NoError(t, idx.MustAdd(md, name+"-"+v+".tgz", "https://example.com", ""))
+		}
+	}
+	idx.SortEntries()
+	return idx
+}
+
+func TestPlanUpgrade(t *testing.T) {
+	oldIdx := newIndex(t, map[string][]string{
+		"argocd":       {"0.3.0", "0.2.0"},
+		"cert-manager": {"1.0.0"},
+		"dex":          {"0.1.0"},
+		"grafana":      {"2.0.0"},
+		"velero":       {"1.0.0"},
+	})
+	newIdx := newIndex(t, map[string][]string{
+		"argocd":       {"0.4.0", "0.3.0"},
+		"cert-manager": {"1.0.0"},
+		"grafana":      {"3.0.0"},
+	})
+	unstableIdx := newIndex(t, map[string][]string{
+		"dex": {"0.2.0-rc.1"},
+	})
+
+	components := []config.Component{
+		{Name: "argocd"},
+		{Name: "certs", ComponentName: "cert-manager"},
+		{Name: "dex"},
+		{Name: "grafana", Version: "2.0.0"},
+		{Name: "velero"},
+		{Name: "loki", Version: "0.1.0-rc.1", Unstable: true},
+	}
+
+	upgrades, warnings := planUpgrade(components, "2022.2", oldIdx, newIdx, unstableIdx)
+	assert.Equal(t, []componentUpgrade{
+		{name: "argocd", from: "0.3.0", to: "0.4.0"},
+	}, upgrades)
+	assert.Equal(t, []string{
+		"Component 'dex' is only available in the unstable repository in Karavel 2022.2",
+		"Component 'grafana' is pinned to version 2.0.0, which is not available in Karavel 2022.2",
+		"Component 'velero' is no longer available in Karavel 2022.2",
+	}, warnings)
+}
+
+func TestUpgradeRenderParams(t *testing.T) {
+	repoUrl := newTestRepository(t)
+	dir := t.TempDir()
+	cpath := filepath.Join(dir, "karavel.hcl")
+	// the project is not in a git repository, so ArgoCD applications can only be rendered skipping git
+	require.NoError(t, os.WriteFile(cpath, []byte(`
+version       = "1970.1"
+stable_repo   = "`+repoUrl+`"
+unstable_repo = "`+repoUrl+`"
+
+component "argocd" {
+  component = "web"
+}
+`), 0o644))
+
+	params := UpgradeParams{ConfigPath: cpath, KaravelVersion: "1970.1", Render: true}
+	assert.ErrorContains(t, Upgrade(testContext(), params), "git")
+
+	params.RenderParams.SkipGit = true
+	require.NoError(t, Upgrade(testContext(), params))
+	assert.FileExists(t, filepath.Join(dir, "applications", "argocd.yml"))
+}
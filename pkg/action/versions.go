@@ -0,0 +1,52 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/karavel-io/cli/internal/release"
+	"github.com/karavel-io/cli/pkg/logger"
+)
+
+// ListVersions returns the platform releases published by the given release source, from the most recent to the oldest
+func ListVersions(ctx context.Context, source string) ([]string, error) {
+	src, err := release.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Infof("Fetching release versions from %s", src)
+	versions, err := src.Releases(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w in %s", release.ErrNoReleases, src)
+	}
+	return versions, nil
+}
+
+func fetchLatestVersion(ctx context.Context, source string) (string, error) {
+	src, err := release.Parse(source)
+	if err != nil {
+		return "", err
+	}
+
+	logger.FromContext(ctx).Infof("Fetching latest release version from %s", src)
+	return release.Latest(ctx, src)
+}
@@ -0,0 +1,320 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/karavel-io/cli/internal/helmw"
+	"github.com/karavel-io/cli/pkg/config"
+	"github.com/karavel-io/cli/pkg/logger"
+)
+
+// maxSummaryFiles caps the number of changed files listed after each render
+const maxSummaryFiles = 20
+
+type WatchParams struct {
+	// Render configures every render. Rendered files cannot be committed nor streamed
+	Render RenderParams
+	// Interval is how often the watched files are checked for changes
+	Interval time.Duration
+}
+
+// Watch renders the project, then renders it again every time the config file or one of the local files it refers to
+// changes, until the context is done. Unchanged components are skipped, and the components repositories are only
+// registered again if the platform version or the repositories change.
+// A summary of the files changed by each render is printed to the Stdout of the render params.
+func Watch(ctx context.Context, params WatchParams) error {
+	if params.Render.Commit {
+		return errors.New("rendered files cannot be committed in watch mode")
+	}
+	if _, stream, err := params.Render.output(); err != nil {
+		return err
+	} else if stream {
+		return fmt.Errorf("output '%s' is not supported in watch mode", params.Render.Output)
+	}
+
+	if params.Interval <= 0 {
+		params.Interval = time.Second
+	}
+
+	log := logger.FromContext(ctx)
+	w := &watcher{
+		log:    log,
+		params: params.Render,
+		files:  []string{params.Render.ConfigPath},
+	}
+	defer w.clean()
+
+	log.Infof("Watching config file %s for changes, press Ctrl+C to stop", params.Render.ConfigPath)
+	stats := statFiles(w.files)
+	w.run(ctx)
+	stats = w.track(stats)
+
+	ticker := time.NewTicker(params.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		next := statFiles(w.files)
+		changed := changedFiles(stats, next)
+		if len(changed) == 0 {
+			continue
+		}
+
+		log.Info()
+		log.Infof("Detected changes to %s", joinNames(changed))
+		stats = next
+		w.run(ctx)
+		stats = w.track(stats)
+	}
+}
+
+// watcher holds the state kept between renders
+type watcher struct {
+	log    logger.Logger
+	params RenderParams
+	// files are the watched files, as listed by the last config successfully read
+	files []string
+	// repoCtx has the components repositories registered, identified by repoKey
+	repoCtx context.Context
+	repoKey string
+}
+
+// run renders the project once, logging failures instead of returning them so that watching can go on
+func (w *watcher) run(ctx context.Context) {
+	start := time.Now()
+	changes, err := w.render(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.log.Errorf("Render failed: %s", err)
+		}
+		return
+	}
+
+	if err := printSummary(w.params.Stdout, changes, time.Since(start)); err != nil {
+		w.log.Warnf("Failed to print render summary: %s", err)
+	}
+}
+
+func (w *watcher) render(ctx context.Context) ([]fileChange, error) {
+	cfg, err := readRenderConfig(w.log, w.params.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	w.files = cfg.Files()
+
+	if err := w.setupRepositories(ctx, &cfg); err != nil {
+		return nil, err
+	}
+
+	workdir := config.ProjectDir(w.params.ConfigPath)
+	before, err := snapshotFiles(workdir, renderedPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := render(w.repoCtx, &cfg, w.params); err != nil {
+		return nil, err
+	}
+
+	after, err := snapshotFiles(workdir, renderedPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffSnapshots(before, after), nil
+}
+
+// setupRepositories registers the components repositories, reusing the ones of the previous render if they did not change.
+// This keeps the Helm cache warm between renders.
+func (w *watcher) setupRepositories(ctx context.Context, cfg *config.Config) error {
+	key := fmt.Sprintf("%s|%s|%s", cfg.Version, cfg.HelmStableRepoUrl, cfg.HelmUnstableRepoUrl)
+	if w.repoCtx != nil && w.repoKey == key {
+		return nil
+	}
+
+	w.clean()
+	rctx, err := setupRepositories(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	w.repoCtx, w.repoKey = rctx, key
+	return nil
+}
+
+// track adds the files watched since the last render to stats, so that they are not reported as changed
+func (w *watcher) track(stats map[string]fileStat) map[string]fileStat {
+	for f, st := range statFiles(w.files) {
+		if _, ok := stats[f]; !ok {
+			stats[f] = st
+		}
+	}
+	return stats
+}
+
+func (w *watcher) clean() {
+	if w.repoCtx != nil {
+		helmw.Clean(w.repoCtx)
+		w.repoCtx, w.repoKey = nil, ""
+	}
+}
+
+// fileStat is what a file is compared on to detect changes. Missing files have a zero fileStat
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFiles(files []string) map[string]fileStat {
+	stats := make(map[string]fileStat, len(files))
+	for _, f := range files {
+		var st fileStat
+		if info, err := os.Stat(f); err == nil {
+			st = fileStat{modTime: info.ModTime(), size: info.Size()}
+		}
+		stats[f] = st
+	}
+	return stats
+}
+
+// changedFiles lists the files of next that were changed, created or deleted since prev, sorted by name
+func changedFiles(prev map[string]fileStat, next map[string]fileStat) []string {
+	var changed []string
+	for f, st := range next {
+		if old, ok := prev[f]; !ok || !old.modTime.Equal(st.modTime) || old.size != st.size {
+			changed = append(changed, f)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+func joinNames(files []string) string {
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = filepath.Base(f)
+	}
+	return strings.Join(names, ", ")
+}
+
+// snapshotFiles maps the files found in paths, relative to workdir, to the digest of their content
+func snapshotFiles(workdir string, paths []string) (map[string]string, error) {
+	snap := make(map[string]string)
+	for _, p := range paths {
+		err := filepath.WalkDir(filepath.Join(workdir, p), func(fp string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+
+			b, err := os.ReadFile(fp)
+			if err != nil {
+				return err
+			}
+
+			rel, err := filepath.Rel(workdir, fp)
+			if err != nil {
+				return err
+			}
+			snap[filepath.ToSlash(rel)] = fmt.Sprintf("%x", sha256.Sum256(b))
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rendered files: %w", err)
+		}
+	}
+	return snap, nil
+}
+
+// fileChange is a file added (+), removed (-) or modified (~) by a render
+type fileChange struct {
+	op   byte
+	path string
+}
+
+// diffSnapshots lists the changes from before to after, sorted by path
+func diffSnapshots(before map[string]string, after map[string]string) []fileChange {
+	var changes []fileChange
+	for p, digest := range after {
+		old, ok := before[p]
+		switch {
+		case !ok:
+			changes = append(changes, fileChange{op: '+', path: p})
+		case old != digest:
+			changes = append(changes, fileChange{op: '~', path: p})
+		}
+	}
+	for p := range before {
+		if _, ok := after[p]; !ok {
+			changes = append(changes, fileChange{op: '-', path: p})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].path < changes[j].path
+	})
+	return changes
+}
+
+func printSummary(out io.Writer, changes []fileChange, elapsed time.Duration) error {
+	stamp := time.Now().Format("15:04:05")
+	elapsed = elapsed.Round(time.Millisecond)
+	if len(changes) == 0 {
+		_, err := fmt.Fprintf(out, "[%s] rendered in %s, no changes\n", stamp, elapsed)
+		return err
+	}
+
+	counts := make(map[byte]int)
+	for _, c := range changes {
+		counts[c.op]++
+	}
+
+	if _, err := fmt.Fprintf(out, "[%s] rendered in %s, %d changed, %d added, %d removed\n",
+		stamp, elapsed, counts['~'], counts['+'], counts['-']); err != nil {
+		return err
+	}
+
+	for i, c := range changes {
+		if i == maxSummaryFiles {
+			_, err := fmt.Fprintf(out, "  ... and %d more\n", len(changes)-maxSummaryFiles)
+			return err
+		}
+		if _, err := fmt.Fprintf(out, "  %c %s\n", c.op, c.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotDiff(t *testing.T) {
+	workdir := t.TempDir()
+	write := func(p string, content string) {
+		p = filepath.Join(workdir, p)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+
+	write("vendor/dex/deployment-dex.yml", "replicas: 1")
+	write("vendor/dex/secret-dex.yml", "data: {}")
+	write("karavel.lock", "version: 1970.1")
+	write("karavel.hcl", "version = \"1970.1\"")
+
+	paths := []string{"vendor", "applications", "karavel.lock"}
+	before, err := snapshotFiles(workdir, paths)
+	require.NoError(t, err)
+	assert.Len(t, before, 3)
+
+	write("vendor/dex/deployment-dex.yml", "replicas: 2")
+	write("vendor/dex/cronjob-dex-gc.yml", "schedule: daily")
+	require.NoError(t, os.Remove(filepath.Join(workdir, "vendor/dex/secret-dex.yml")))
+
+	after, err := snapshotFiles(workdir, paths)
+	require.NoError(t, err)
+
+	changes := diffSnapshots(before, after)
+	assert.Equal(t, []fileChange{
+		{op: '+', path: "vendor/dex/cronjob-dex-gc.yml"},
+		{op: '~', path: "vendor/dex/deployment-dex.yml"},
+		{op: '-', path: "vendor/dex/secret-dex.yml"},
+	}, changes)
+
+	var out bytes.Buffer
+	require.NoError(t, printSummary(&out, changes, time.Second))
+	assert.Contains(t, out.String(), "1 changed, 1 added, 1 removed\n  + vendor/dex/cronjob-dex-gc.yml\n  ~ vendor/dex/deployment-dex.yml\n  - vendor/dex/secret-dex.yml\n")
+}
+
+func TestChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, "karavel.hcl")
+	patch := filepath.Join(dir, "patch.yml")
+	require.NoError(t, os.WriteFile(cfg, []byte("version = \"1970.1\""), 0o644))
+
+	files := []string{cfg, patch}
+	stats := statFiles(files)
+	assert.Empty(t, changedFiles(stats, statFiles(files)))
+
+	require.NoError(t, os.WriteFile(patch, []byte("[]"), 0o644))
+	assert.Equal(t, []string{patch}, changedFiles(stats, statFiles(files)))
+
+	require.NoError(t, os.WriteFile(cfg, []byte("version = \"1970.2\""), 0o644))
+	assert.Equal(t, []string{cfg, patch}, changedFiles(stats, statFiles(files)))
+}
+
+func TestWatchUnsupportedParams(t *testing.T) {
+	err := Watch(context.Background(), WatchParams{Render: RenderParams{Commit: true}})
+	assert.ErrorContains(t, err, "cannot be committed")
+
+	err = Watch(context.Background(), WatchParams{Render: RenderParams{Output: OutputStdout}})
+	assert.ErrorContains(t, err, "not supported in watch mode")
+}
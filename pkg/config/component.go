@@ -14,14 +14,121 @@
 
 package config
 
-import "github.com/hashicorp/hcl/v2"
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
 
 type Component struct {
-	Name          string                    `hcl:"name,label"`
-	ComponentName string                    `hcl:"component,optional"`
-	Namespace     string                    `hcl:"namespace,optional"`
-	Version       string                    `hcl:"version,optional"`
-	RawParams     map[string]*hcl.Attribute `hcl:",remain"`
-	JsonParams    string
-	Unstable      bool
+	Name          string            `hcl:"name,label"`
+	ComponentName string            `hcl:"component,optional"`
+	Namespace     string            `hcl:"namespace,optional"`
+	Version       string            `hcl:"version,optional"`
+	Secrets       *ComponentSecrets `hcl:"secrets,block"`
+	Kustomize     *Kustomize        `hcl:"kustomize,block"`
+	PostRenderers []PostRenderer    `hcl:"post_renderer,block"`
+	// Layout overrides the global layout for this component
+	Layout     string   `hcl:"layout,optional"`
+	Remain     hcl.Body `hcl:",remain"`
+	RawParams  map[string]*hcl.Attribute
+	JsonParams string
+	// SecretParams lists the paths of the params decrypted from secret files
+	SecretParams []string
+	Unstable     bool
+}
+
+const (
+	SecretsModeSealed   = "sealed"
+	SecretsModeExternal = "external"
+)
+
+// ComponentSecrets marks the Secrets rendered by a component to be replaced before being written to disk
+type ComponentSecrets struct {
+	// Mode is either sealed or external
+	Mode string `hcl:"mode"`
+	// Names restricts the replacement to the listed Secrets. All Secrets are replaced if empty
+	Names []string `hcl:"names,optional"`
+}
+
+// Kustomize customizes the resources rendered by a component through its kustomization file
+type Kustomize struct {
+	CommonLabels      map[string]string `hcl:"common_labels,optional"`
+	CommonAnnotations map[string]string `hcl:"common_annotations,optional"`
+	Images            []KustomizeImage  `hcl:"image,block"`
+	Patches           []KustomizePatch  `hcl:"patch,block"`
+}
+
+// KustomizeImage overrides the name, tag or digest of a container image
+type KustomizeImage struct {
+	Name    string `hcl:"name,label"`
+	NewName string `hcl:"new_name,optional"`
+	NewTag  string `hcl:"new_tag,optional"`
+	Digest  string `hcl:"digest,optional"`
+}
+
+// KustomizePatch is a strategic merge or JSON 6902 patch, either inline or read from a file
+type KustomizePatch struct {
+	// Path is relative to the config file. The patch is inlined in the kustomization file
+	Path    string           `hcl:"path,optional"`
+	Content string           `hcl:"content,optional"`
+	Target  *KustomizeTarget `hcl:"target,block"`
+}
+
+// KustomizeTarget selects the resources a patch applies to
+type KustomizeTarget struct {
+	Group              string `hcl:"group,optional"`
+	Version            string `hcl:"version,optional"`
+	Kind               string `hcl:"kind,optional"`
+	Name               string `hcl:"name,optional"`
+	Namespace          string `hcl:"namespace,optional"`
+	LabelSelector      string `hcl:"label_selector,optional"`
+	AnnotationSelector string `hcl:"annotation_selector,optional"`
+}
+
+// componentAttributes and componentBlocks are the contents of a component block that are not params
+var componentAttributes, componentBlocks = func() (map[string]bool, map[string]bool) {
+	schema, _ := gohcl.ImpliedBodySchema(Component{})
+	attrs := make(map[string]bool, len(schema.Attributes))
+	for _, a := range schema.Attributes {
+		attrs[a.Name] = true
+	}
+	blocks := make(map[string]bool, len(schema.Blocks))
+	for _, b := range schema.Blocks {
+		blocks[b.Type] = true
+	}
+	return attrs, blocks
+}()
+
+// paramAttributes returns the params declared in the remaining body of a component block.
+// Native syntax bodies are read directly, as their JustAttributes rejects the nested blocks
+// that have already been decoded.
+func paramAttributes(body hcl.Body) (hcl.Attributes, hcl.Diagnostics) {
+	sb, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return body.JustAttributes()
+	}
+
+	var diags hcl.Diagnostics
+	for _, b := range sb.Blocks {
+		if !componentBlocks[b.Type] {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Unexpected %q block", b.Type),
+				Detail:   "Blocks are not allowed here.",
+				Subject:  &b.TypeRange,
+			})
+		}
+	}
+
+	attrs := make(hcl.Attributes, len(sb.Attributes))
+	for name, a := range sb.Attributes {
+		if componentAttributes[name] {
+			continue
+		}
+		attrs[name] = a.AsHCLAttribute()
+	}
+	return attrs, diags
 }
@@ -16,7 +16,10 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"io"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/karavel-io/cli/internal/helmw"
@@ -35,36 +38,136 @@ type Config struct {
 	Components          []Component `hcl:"component,block"`
 	HelmStableRepoUrl   string      `hcl:"stable_repo,optional"`
 	HelmUnstableRepoUrl string      `hcl:"unstable_repo,optional"`
+	// CliVersion is a semver constraint on the Karavel CLI versions allowed to render the project
+	CliVersion      string           `hcl:"cli_version,optional"`
+	SealedSecrets   *SealedSecrets   `hcl:"sealed_secrets,block"`
+	ExternalSecrets *ExternalSecrets `hcl:"external_secrets,block"`
+	// PostRenderers run on the documents of every component, before the component ones
+	PostRenderers []PostRenderer `hcl:"post_renderer,block"`
+	// RegistryMirrors maps registry hosts to the mirrors container images are rewritten to
+	RegistryMirrors map[string]string `hcl:"registry_mirrors,optional"`
+	// InsecureRegistries are the registry hosts accessed over plain HTTP when pinning image digests
+	InsecureRegistries []string `hcl:"insecure_registries,optional"`
+	// Layout is how component resources are laid out in the vendor directory, one of flat, by-kind or single-file
+	Layout string `hcl:"layout,optional"`
+
+	// Includes are the fragments merged into the config
+	Includes []Include `hcl:"include,block"`
+
+	// sources are the config files the config was read from, mainFile the one declaring the version
+	sources  []string
+	mainFile string
+	// componentFiles maps the components to the config file declaring them
+	componentFiles map[string]string
+	// decrypted are the encrypted files read while evaluating the params
+	decrypted []string
+}
+
+const (
+	LayoutFlat       = "flat"
+	LayoutByKind     = "by-kind"
+	LayoutSingleFile = "single-file"
+)
+
+// SealedSecrets configures the encryption of component Secrets into SealedSecrets
+type SealedSecrets struct {
+	// Certificate is the path to the controller public certificate, relative to the config file
+	Certificate string `hcl:"certificate"`
+	// Scope is one of strict, namespace-wide or cluster-wide
+	Scope string `hcl:"scope,optional"`
 }
 
+// ExternalSecrets configures the ExternalSecret references replacing component Secrets
+type ExternalSecrets struct {
+	Store           string `hcl:"store"`
+	StoreKind       string `hcl:"store_kind,optional"`
+	RefreshInterval string `hcl:"refresh_interval,optional"`
+	KeyPrefix       string `hcl:"key_prefix,optional"`
+}
+
+// ReadFrom reads the config from a file, or from the *.hcl files of a directory, along with the files they include
 func ReadFrom(logw io.Writer, filename string) (Config, error) {
 	var c Config
 
 	p := hclparse.NewParser()
 
 	w := hcl.NewDiagnosticTextWriter(logw, p.Files(), 79, true)
-	f, err := p.ParseHCLFile(filename)
+	filenames, err := configFiles(filename)
 	if err != nil {
-		_ = w.WriteDiagnostics(err)
-		if err.HasErrors() {
+		return c, err
+	}
+
+	files, diags := parseFiles(p, filenames)
+	if len(diags) > 0 {
+		_ = w.WriteDiagnostics(diags)
+		if diags.HasErrors() {
 			return c, ErrConfigParseFailed
 		}
 	}
 
-	if err := gohcl.DecodeBody(f.Body, nil, &c); err != nil {
+	compFiles, diags := componentFiles(files)
+	if diags.HasErrors() {
+		_ = w.WriteDiagnostics(diags)
+		return c, ErrConfigParseFailed
+	}
+
+	if err := gohcl.DecodeBody(hcl.MergeFiles(files), nil, &c); err != nil {
 		_ = w.WriteDiagnostics(err)
 		if err.HasErrors() {
 			return c, ErrConfigParseFailed
 		}
 	}
 
+	for _, f := range files {
+		c.sources = append(c.sources, f.Body.MissingItemRange().Filename)
+	}
+	c.mainFile = versionFile(files)
+	c.componentFiles = compFiles
+
+	if c.SealedSecrets != nil && !filepath.IsAbs(c.SealedSecrets.Certificate) {
+		dir := declaringDir(findBlocks(files, hcl.BlockHeaderSchema{Type: "sealed_secrets"}), 0, filename)
+		c.SealedSecrets.Certificate = filepath.Join(dir, c.SealedSecrets.Certificate)
+	}
+
+	prBlocks := findBlocks(files, hcl.BlockHeaderSchema{Type: "post_renderer", LabelNames: []string{"type"}})
+	for i := range c.PostRenderers {
+		if err := resolvePostRenderers(declaringDir(prBlocks, i, filename), c.PostRenderers[i:i+1]); err != nil {
+			return c, err
+		}
+	}
+
+	if c.Layout == "" {
+		c.Layout = LayoutFlat
+	}
+	if err := validateLayout(c.Layout); err != nil {
+		return c, err
+	}
+
+	// values are evaluated relative to the file declaring their component
+	evalCtxs := make(map[string]*hcl.EvalContext)
 	for i := range c.Components {
 		cc := &c.Components[i]
 		cc.Name = strings.ToLower(cc.Name)
+		basedir := filepath.Dir(compFiles[cc.Name])
+
+		evalCtx, ok := evalCtxs[basedir]
+		if !ok {
+			evalCtx = newEvalContext(basedir, func(file string) {
+				c.decrypted = append(c.decrypted, file)
+			})
+			evalCtxs[basedir] = evalCtx
+		}
+
+		attrs, diags := paramAttributes(cc.Remain)
+		if diags.HasErrors() {
+			_ = w.WriteDiagnostics(diags)
+			return c, ErrConfigParseFailed
+		}
+		cc.RawParams = attrs
 
 		pp := make(map[string]cty.Value)
 		for l, a := range cc.RawParams {
-			v, err := a.Expr.Value(nil)
+			v, err := a.Expr.Value(evalCtx)
 			if err != nil {
 				_ = w.WriteDiagnostics(err)
 				if err.HasErrors() {
@@ -73,7 +176,10 @@ func ReadFrom(logw io.Writer, filename string) (Config, error) {
 			}
 			pp[l] = v
 		}
-		m := cty.ObjectVal(pp)
+		m, secrets := secretPaths(cty.ObjectVal(pp))
+		sort.Strings(secrets)
+		cc.SecretParams = secrets
+
 		j, jerr := json.Marshal(m, m.Type())
 		if jerr != nil {
 			return c, jerr
@@ -84,6 +190,25 @@ func ReadFrom(logw io.Writer, filename string) (Config, error) {
 			cc.Unstable = true
 		}
 		cc.JsonParams = string(j)
+
+		if err := c.validateSecrets(cc); err != nil {
+			return c, err
+		}
+
+		if err := resolvePatches(basedir, cc); err != nil {
+			return c, err
+		}
+
+		if err := resolvePostRenderers(basedir, cc.PostRenderers); err != nil {
+			return c, fmt.Errorf("component '%s': %w", cc.Name, err)
+		}
+
+		if cc.Layout == "" {
+			cc.Layout = c.Layout
+		}
+		if err := validateLayout(cc.Layout); err != nil {
+			return c, fmt.Errorf("component '%s': %w", cc.Name, err)
+		}
 	}
 
 	c.HelmStableRepoUrl = helmw.GetRepoUrl(c.Version, c.HelmStableRepoUrl)
@@ -91,3 +216,117 @@ func ReadFrom(logw io.Writer, filename string) (Config, error) {
 
 	return c, nil
 }
+
+// Sources lists the config files the config was read from, including the ones it includes
+func (c *Config) Sources() []string {
+	return c.sources
+}
+
+// MainFile returns the config file declaring the platform version, where new components are added
+func (c *Config) MainFile() string {
+	return c.mainFile
+}
+
+// ComponentFile returns the config file declaring the named component, if any
+func (c *Config) ComponentFile(name string) string {
+	return c.componentFiles[strings.ToLower(name)]
+}
+
+// Files lists the local files the config depends on: the config file itself, along with the certificates,
+// patches, post-renderer commands and encrypted files it refers to
+func (c *Config) Files() []string {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(f string) {
+		if f != "" && !seen[f] {
+			seen[f] = true
+			files = append(files, f)
+		}
+	}
+	addCommands := func(prs []PostRenderer) {
+		for _, pr := range prs {
+			// commands without a path are looked up in PATH
+			if pr.Type == PostRendererExec && strings.ContainsRune(filepath.ToSlash(pr.Command), '/') {
+				add(pr.Command)
+			}
+		}
+	}
+
+	for _, f := range c.sources {
+		add(f)
+	}
+	for _, f := range c.decrypted {
+		add(f)
+	}
+	if c.SealedSecrets != nil {
+		add(c.SealedSecrets.Certificate)
+	}
+	addCommands(c.PostRenderers)
+	for _, cc := range c.Components {
+		if cc.Kustomize != nil {
+			for _, p := range cc.Kustomize.Patches {
+				add(p.Path)
+			}
+		}
+		addCommands(cc.PostRenderers)
+	}
+
+	sort.Strings(files)
+	return files
+}
+
+// declaringDir returns the directory of the file declaring the i-th block, or the one of fallback if it cannot be found
+func declaringDir(blocks hcl.Blocks, i int, fallback string) string {
+	if i < len(blocks) {
+		return filepath.Dir(blocks[i].DefRange.Filename)
+	}
+	return ProjectDir(fallback)
+}
+
+func (c *Config) validateSecrets(cc *Component) error {
+	if cc.Secrets == nil {
+		return nil
+	}
+
+	switch cc.Secrets.Mode {
+	case SecretsModeSealed:
+		if c.SealedSecrets == nil {
+			return fmt.Errorf("component '%s' uses sealed secrets, but no sealed_secrets block is configured", cc.Name)
+		}
+	case SecretsModeExternal:
+		if c.ExternalSecrets == nil {
+			return fmt.Errorf("component '%s' uses external secrets, but no external_secrets block is configured", cc.Name)
+		}
+	default:
+		return fmt.Errorf("component '%s' has unsupported secrets mode '%s', must be one of %s, %s", cc.Name, cc.Secrets.Mode, SecretsModeSealed, SecretsModeExternal)
+	}
+	return nil
+}
+
+func validateLayout(layout string) error {
+	switch layout {
+	case LayoutFlat, LayoutByKind, LayoutSingleFile:
+		return nil
+	default:
+		return fmt.Errorf("unsupported layout '%s', must be one of %s, %s, %s", layout, LayoutFlat, LayoutByKind, LayoutSingleFile)
+	}
+}
+
+// resolvePatches checks that each patch is either inline or read from a file, resolving file paths against basedir
+func resolvePatches(basedir string, cc *Component) error {
+	if cc.Kustomize == nil {
+		return nil
+	}
+
+	for i := range cc.Kustomize.Patches {
+		p := &cc.Kustomize.Patches[i]
+		if (p.Path == "") == (p.Content == "") {
+			return fmt.Errorf("component '%s' patch #%d must set exactly one of path or content", cc.Name, i+1)
+		}
+
+		if p.Path != "" && !filepath.IsAbs(p.Path) {
+			p.Path = filepath.Join(basedir, p.Path)
+		}
+	}
+	return nil
+}
@@ -18,10 +18,13 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/karavel-io/cli/internal/helmw"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -123,3 +126,225 @@ func (s *ConfigTestSuite) TestEdge() {
 func TestReadFrom(t *testing.T) {
 	suite.Run(t, new(ConfigTestSuite))
 }
+
+func TestSecretsConfig(t *testing.T) {
+	dir := t.TempDir()
+	cpath := filepath.Join(dir, "karavel.hcl")
+	require.NoError(t, ioutil.WriteFile(cpath, []byte(`
+version = "1970.1"
+
+sealed_secrets {
+  certificate = "sealed-secrets.pem"
+}
+
+component "dex" {
+  namespace = "dex"
+
+  secrets {
+    mode  = "sealed"
+    names = ["dex-oidc"]
+  }
+
+  hello = "world"
+}
+`), 0o644))
+
+	cfg, err := ReadFrom(ioutil.Discard, cpath)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "sealed-secrets.pem"), cfg.SealedSecrets.Certificate)
+
+	c := cfg.Components[0]
+	assert.Equal(t, &ComponentSecrets{Mode: SecretsModeSealed, Names: []string{"dex-oidc"}}, c.Secrets)
+	assert.Equal(t, `{"hello":"world"}`, c.JsonParams)
+
+	require.NoError(t, ioutil.WriteFile(cpath, []byte(`
+version = "1970.1"
+
+component "dex" {
+  secrets {
+    mode = "external"
+  }
+}
+`), 0o644))
+
+	_, err = ReadFrom(ioutil.Discard, cpath)
+	assert.ErrorContains(t, err, "no external_secrets block")
+}
+
+func TestKustomizeConfig(t *testing.T) {
+	dir := t.TempDir()
+	cpath := filepath.Join(dir, "karavel.hcl")
+	require.NoError(t, ioutil.WriteFile(cpath, []byte(`
+version = "1970.1"
+
+component "dex" {
+  kustomize {
+    common_labels = { team = "platform" }
+
+    image "ghcr.io/dexidp/dex" {
+      new_tag = "v2.31.0"
+    }
+
+    patch {
+      path = "patches/dex.yml"
+    }
+
+    patch {
+      content = "[{\"op\": \"remove\", \"path\": \"/spec/replicas\"}]"
+      target {
+        kind = "Deployment"
+        name = "dex"
+      }
+    }
+  }
+}
+`), 0o644))
+
+	cfg, err := ReadFrom(ioutil.Discard, cpath)
+	require.NoError(t, err)
+
+	k := cfg.Components[0].Kustomize
+	require.NotNil(t, k)
+	assert.Equal(t, map[string]string{"team": "platform"}, k.CommonLabels)
+	assert.Equal(t, []KustomizeImage{{Name: "ghcr.io/dexidp/dex", NewTag: "v2.31.0"}}, k.Images)
+	require.Len(t, k.Patches, 2)
+	assert.Equal(t, filepath.Join(dir, "patches/dex.yml"), k.Patches[0].Path)
+	assert.Equal(t, &KustomizeTarget{Kind: "Deployment", Name: "dex"}, k.Patches[1].Target)
+
+	require.NoError(t, ioutil.WriteFile(cpath, []byte(`
+version = "1970.1"
+
+component "dex" {
+  kustomize {
+    patch {}
+  }
+}
+`), 0o644))
+
+	_, err = ReadFrom(ioutil.Discard, cpath)
+	assert.ErrorContains(t, err, "exactly one of path or content")
+}
+
+func TestPostRenderersConfig(t *testing.T) {
+	dir := t.TempDir()
+	cpath := filepath.Join(dir, "karavel.hcl")
+	require.NoError(t, ioutil.WriteFile(cpath, []byte(`
+version = "1970.1"
+
+registry_mirrors = {
+  "docker.io" = "mirror.local/dockerhub"
+}
+
+insecure_registries = ["registry.local:5000"]
+
+post_renderer "labels" {
+  labels = { team = "platform" }
+}
+
+component "dex" {
+  post_renderer "exec" {
+    command = "./scripts/patch.sh"
+    args    = ["--dex"]
+  }
+
+  post_renderer "namespace" {}
+}
+`), 0o644))
+
+	cfg, err := ReadFrom(ioutil.Discard, cpath)
+	require.NoError(t, err)
+	assert.Equal(t, []PostRenderer{{Type: PostRendererLabels, Labels: map[string]string{"team": "platform"}}}, cfg.PostRenderers)
+	assert.Equal(t, map[string]string{"docker.io": "mirror.local/dockerhub"}, cfg.RegistryMirrors)
+	assert.Equal(t, []string{"registry.local:5000"}, cfg.InsecureRegistries)
+	assert.Equal(t, []PostRenderer{
+		{Type: PostRendererExec, Command: filepath.Join(dir, "scripts/patch.sh"), Args: []string{"--dex"}},
+		{Type: PostRendererNamespace},
+	}, cfg.Components[0].PostRenderers)
+
+	require.NoError(t, ioutil.WriteFile(cpath, []byte(`
+version = "1970.1"
+
+post_renderer "registry" {}
+`), 0o644))
+
+	_, err = ReadFrom(ioutil.Discard, cpath)
+	assert.ErrorContains(t, err, "registry post-renderer requires 'rewrites'")
+
+	require.NoError(t, ioutil.WriteFile(cpath, []byte(`
+version = "1970.1"
+
+post_renderer "nope" {}
+`), 0o644))
+
+	_, err = ReadFrom(ioutil.Discard, cpath)
+	assert.ErrorContains(t, err, "unsupported post-renderer 'nope'")
+}
+
+func TestLayoutConfig(t *testing.T) {
+	dir := t.TempDir()
+	cpath := filepath.Join(dir, "karavel.hcl")
+	require.NoError(t, ioutil.WriteFile(cpath, []byte(`
+version = "1970.1"
+layout  = "by-kind"
+
+component "dex" {}
+
+component "argocd" {
+  layout = "single-file"
+}
+`), 0o644))
+
+	cfg, err := ReadFrom(ioutil.Discard, cpath)
+	require.NoError(t, err)
+	assert.Equal(t, LayoutByKind, cfg.Components[0].Layout)
+	assert.Equal(t, LayoutSingleFile, cfg.Components[1].Layout)
+
+	require.NoError(t, ioutil.WriteFile(cpath, []byte(`
+version = "1970.1"
+
+component "dex" {
+  layout = "nested"
+}
+`), 0o644))
+
+	_, err = ReadFrom(ioutil.Discard, cpath)
+	assert.ErrorContains(t, err, "component 'dex': unsupported layout 'nested'")
+}
+
+func TestConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	cpath := filepath.Join(dir, "karavel.hcl")
+	require.NoError(t, ioutil.WriteFile(cpath, []byte(`
+version = "1970.1"
+
+sealed_secrets {
+  certificate = "sealed-secrets.pem"
+}
+
+post_renderer "exec" {
+  command = "./scripts/render.sh"
+}
+
+post_renderer "exec" {
+  command = "kbld"
+}
+
+component "dex" {
+  kustomize {
+    patch {
+      path = "patches/dex.yml"
+    }
+  }
+}
+`), 0o644))
+
+	cfg, err := ReadFrom(ioutil.Discard, cpath)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		cpath,
+		filepath.Join(dir, "patches/dex.yml"),
+		filepath.Join(dir, "scripts/render.sh"),
+		filepath.Join(dir, "sealed-secrets.pem"),
+	}, cfg.Files())
+}
@@ -0,0 +1,437 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// Convert translates the source of a config file to another format. The format of the source is selected
+// from the extension of filename.
+//
+// Values that are not literals, like function calls, are carried over as "${...}" interpolations, since that
+// is how the JSON syntax of HCL expresses them. Comments are not preserved.
+func Convert(src []byte, filename string, to Format) ([]byte, error) {
+	schema := schemaOf(reflect.TypeOf(Config{}))
+
+	var b *body
+	switch FormatOf(filename) {
+	case FormatHCL:
+		f, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("failed to parse config file %s: %s", filename, diags.Error())
+		}
+		b = readHCLBody(f.Body.(*hclsyntax.Body), src)
+	case FormatJSON, FormatYAML:
+		var v any
+		var err error
+		if FormatOf(filename) == FormatJSON {
+			v, err = decodeJSON(src)
+		} else {
+			v, err = decodeYAML(src)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", filename, err)
+		}
+		b, err = readGenericBody(v, schema)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", filename, err)
+		}
+	}
+
+	switch to {
+	case FormatHCL:
+		return writeHCL(b), nil
+	case FormatJSON:
+		return encodeJSON(b.generic(), "  ")
+	case FormatYAML:
+		return encodeYAML(b.generic())
+	default:
+		return nil, fmt.Errorf("unsupported config format '%s'", to)
+	}
+}
+
+// body is a config body independent of its syntax. Its items are either attributes or blocks,
+// kept in the order they were declared in
+type body struct {
+	items []any
+}
+
+type attribute struct {
+	name string
+	// value follows the JSON syntax of HCL: strings are templates, and expressions are written as "${...}"
+	value any
+}
+
+type block struct {
+	typ    string
+	labels []string
+	body   *body
+}
+
+// blockSchema describes the blocks a body can contain, which is what tells them apart from
+// object attributes in the JSON and YAML formats
+type blockSchema struct {
+	labels int
+	blocks map[string]*blockSchema
+}
+
+func schemaOf(t reflect.Type) *blockSchema {
+	s := &blockSchema{blocks: make(map[string]*blockSchema)}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("hcl")
+		if !ok {
+			continue
+		}
+
+		name, kind, _ := strings.Cut(tag, ",")
+		switch kind {
+		case "label":
+			s.labels++
+		case "block":
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr || ft.Kind() == reflect.Slice {
+				ft = ft.Elem()
+			}
+			s.blocks[name] = schemaOf(ft)
+		}
+	}
+	return s
+}
+
+func readHCLBody(b *hclsyntax.Body, src []byte) *body {
+	type positioned struct {
+		pos  int
+		item any
+	}
+
+	var items []positioned
+	for _, a := range b.Attributes {
+		items = append(items, positioned{a.SrcRange.Start.Byte, &attribute{name: a.Name, value: readHCLExpr(a.Expr, src)}})
+	}
+	for _, blk := range b.Blocks {
+		items = append(items, positioned{blk.TypeRange.Start.Byte, &block{typ: blk.Type, labels: blk.Labels, body: readHCLBody(blk.Body, src)}})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].pos < items[j].pos
+	})
+
+	res := &body{items: make([]any, len(items))}
+	for i, it := range items {
+		res.items[i] = it.item
+	}
+	return res
+}
+
+func readHCLExpr(expr hclsyntax.Expression, src []byte) any {
+	switch e := expr.(type) {
+	case *hclsyntax.ObjectConsExpr:
+		m := newOrderedMap()
+		for _, item := range e.Items {
+			key, diags := item.KeyExpr.Value(nil)
+			if diags.HasErrors() || !key.IsWhollyKnown() || key.IsNull() || !key.Type().Equals(cty.String) {
+				return interpolation(expr, src)
+			}
+			m.set(escapeTemplate(key.AsString()), readHCLExpr(item.ValueExpr, src))
+		}
+		return m
+	case *hclsyntax.TupleConsExpr:
+		l := make([]any, 0, len(e.Exprs))
+		for _, ex := range e.Exprs {
+			l = append(l, readHCLExpr(ex, src))
+		}
+		return l
+	case *hclsyntax.TemplateWrapExpr:
+		return interpolation(e.Wrapped, src)
+	}
+
+	if v, diags := expr.Value(nil); !diags.HasErrors() && v.IsNull() {
+		return nil
+	} else if !diags.HasErrors() && v.IsWhollyKnown() && v.Type().IsPrimitiveType() {
+		if v.Type().Equals(cty.String) {
+			return escapeTemplate(v.AsString())
+		}
+		if js, err := ctyjson.Marshal(v, v.Type()); err == nil {
+			if v, err := decodeJSON(js); err == nil {
+				return v
+			}
+		}
+	}
+
+	// templates mixing literals and interpolations keep their parts, unless they contain directives
+	if t, ok := expr.(*hclsyntax.TemplateExpr); ok && !bytes.Contains(exprSource(expr, src), []byte("%{")) {
+		var sb strings.Builder
+		for _, p := range t.Parts {
+			if lit, ok := p.(*hclsyntax.LiteralValueExpr); ok && lit.Val.Type().Equals(cty.String) {
+				sb.WriteString(escapeTemplate(lit.Val.AsString()))
+			} else {
+				sb.WriteString(interpolation(p, src))
+			}
+		}
+		return sb.String()
+	}
+
+	return interpolation(expr, src)
+}
+
+func exprSource(expr hclsyntax.Expression, src []byte) []byte {
+	rng := expr.Range()
+	return src[rng.Start.Byte:rng.End.Byte]
+}
+
+func interpolation(expr hclsyntax.Expression, src []byte) string {
+	return "${" + string(exprSource(expr, src)) + "}"
+}
+
+// escapeTemplate escapes a literal string so that it is not read as a template
+func escapeTemplate(s string) string {
+	s = strings.ReplaceAll(s, "${", "$${")
+	return strings.ReplaceAll(s, "%{", "%%{")
+}
+
+func readGenericBody(v any, schema *blockSchema) (*body, error) {
+	m, ok := v.(*orderedMap)
+	if !ok {
+		return nil, fmt.Errorf("expected an object, found %s", describe(v))
+	}
+
+	b := &body{}
+	for _, k := range m.keys {
+		s, ok := schema.blocks[k]
+		if !ok {
+			b.items = append(b.items, &attribute{name: k, value: m.values[k]})
+			continue
+		}
+
+		if err := readGenericBlocks(b, k, nil, m.values[k], s); err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+	}
+	return b, nil
+}
+
+// readGenericBlocks reads blocks of type typ, consuming one level of nested objects for each label.
+// Arrays declare several blocks at any level
+func readGenericBlocks(b *body, typ string, labels []string, v any, schema *blockSchema) error {
+	if l, ok := v.([]any); ok {
+		for _, e := range l {
+			if err := readGenericBlocks(b, typ, labels, e, schema); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(labels) < schema.labels {
+		m, ok := v.(*orderedMap)
+		if !ok {
+			return fmt.Errorf("expected an object keyed by label, found %s", describe(v))
+		}
+		for _, k := range m.keys {
+			if err := readGenericBlocks(b, typ, append(labels[:len(labels):len(labels)], k), m.values[k], schema); err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+		}
+		return nil
+	}
+
+	blk, err := readGenericBody(v, schema)
+	if err != nil {
+		return err
+	}
+	b.items = append(b.items, &block{typ: typ, labels: labels, body: blk})
+	return nil
+}
+
+func describe(v any) string {
+	switch v.(type) {
+	case *orderedMap:
+		return "an object"
+	case []any:
+		return "an array"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%q", fmt.Sprint(v))
+	}
+}
+
+// generic returns the body in the JSON syntax of HCL. Blocks are nested by label, and blocks sharing
+// the same type and labels are collected in an array
+func (b *body) generic() *orderedMap {
+	m := newOrderedMap()
+	for _, it := range b.items {
+		switch t := it.(type) {
+		case *attribute:
+			m.set(t.name, t.value)
+		case *block:
+			parent := m
+			key := t.typ
+			for _, l := range t.labels {
+				next, ok := parent.values[key].(*orderedMap)
+				if !ok {
+					next = newOrderedMap()
+					parent.set(key, next)
+				}
+				parent, key = next, l
+			}
+
+			v := t.body.generic()
+			switch existing := parent.values[key].(type) {
+			case nil:
+				parent.set(key, v)
+			case []any:
+				parent.set(key, append(existing, v))
+			default:
+				parent.set(key, []any{existing, v})
+			}
+		}
+	}
+	return m
+}
+
+func writeHCL(b *body) []byte {
+	f := hclwrite.NewEmptyFile()
+	writeHCLBody(f.Body(), b)
+	return hclwrite.Format(f.Bytes())
+}
+
+func writeHCLBody(out *hclwrite.Body, b *body) {
+	for i, it := range b.items {
+		switch t := it.(type) {
+		case *attribute:
+			expr := hclExpr(t.value, "")
+			if s, ok := t.value.(string); ok && isHeredoc(s) {
+				expr = "<<EOT\n" + s + "EOT"
+			}
+			out.SetAttributeRaw(t.name, hclwrite.Tokens{{Type: hclsyntax.TokenIdent, Bytes: []byte(expr)}})
+		case *block:
+			// keep blocks apart from what precedes them, the way they are usually laid out by hand
+			if i > 0 {
+				out.AppendNewline()
+			}
+			writeHCLBody(out.AppendNewBlock(t.typ, t.labels).Body(), t.body)
+		}
+	}
+}
+
+// isHeredoc tells whether a multi-line string reads better as a heredoc, where escapes are not needed
+func isHeredoc(s string) bool {
+	if !strings.HasSuffix(s, "\n") || strings.Count(s, "\n") < 2 {
+		return false
+	}
+	for _, l := range strings.Split(s, "\n") {
+		if strings.TrimSpace(l) == "EOT" {
+			return false
+		}
+	}
+	return true
+}
+
+// hclExpr writes a value of the JSON syntax as a native HCL expression
+func hclExpr(v any, indent string) string {
+	switch t := v.(type) {
+	case *orderedMap:
+		if len(t.keys) == 0 {
+			return "{}"
+		}
+		var sb strings.Builder
+		sb.WriteString("{\n")
+		for _, k := range t.keys {
+			sb.WriteString(indent + "  ")
+			if hclsyntax.ValidIdentifier(k) {
+				sb.WriteString(k)
+			} else {
+				sb.WriteString(hclString(k))
+			}
+			sb.WriteString(" = ")
+			sb.WriteString(hclExpr(t.values[k], indent+"  "))
+			sb.WriteString("\n")
+		}
+		sb.WriteString(indent + "}")
+		return sb.String()
+	case []any:
+		parts := make([]string, len(t))
+		multiline := false
+		for i, e := range t {
+			parts[i] = hclExpr(e, indent+"  ")
+			if strings.Contains(parts[i], "\n") {
+				multiline = true
+			}
+		}
+		if !multiline {
+			return "[" + strings.Join(parts, ", ") + "]"
+		}
+		return "[\n" + indent + "  " + strings.Join(parts, ",\n"+indent+"  ") + ",\n" + indent + "]"
+	case string:
+		// a lone interpolation is the expression itself, keeping its type
+		if e, diags := hclsyntax.ParseTemplate([]byte(t), "", hcl.InitialPos); !diags.HasErrors() {
+			if w, ok := e.(*hclsyntax.TemplateWrapExpr); ok {
+				rng := w.Wrapped.Range()
+				return t[rng.Start.Byte:rng.End.Byte]
+			}
+		}
+		return hclString(t)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// hclString quotes a template, escaping its literal parts only
+func hclString(s string) string {
+	tokens, diags := hclsyntax.LexTemplate([]byte(s), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		b, _ := json.Marshal(s)
+		return string(b)
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('"')
+	prev := 0
+	for _, tok := range tokens {
+		if tok.Type == hclsyntax.TokenEOF {
+			break
+		}
+		end := tok.Range.End.Byte
+		if tok.Type == hclsyntax.TokenStringLit || tok.Type == hclsyntax.TokenQuotedLit {
+			sb.WriteString(s[prev:tok.Range.Start.Byte])
+			sb.WriteString(escapeHCLLiteral(string(tok.Bytes)))
+		} else {
+			sb.WriteString(s[prev:end])
+		}
+		prev = end
+	}
+	sb.WriteString(s[prev:])
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+func escapeHCLLiteral(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(s)
+}
@@ -0,0 +1,189 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const convertHCL = `version = "1970.1"
+
+external_secrets {
+  store = "vault"
+}
+
+post_renderer "labels" {
+  labels = {
+    team = "platform"
+  }
+}
+
+component "dex" {
+  namespace = "dex"
+  replicas  = 2
+  ingress = {
+    host = "dex.${sops("dex.enc.yaml", "domain")}.com"
+    tls  = true
+  }
+  clientSecret = sops("dex.enc.yaml", "secret")
+  banner       = "costs $${HOME} \"quoted\""
+
+  secrets {
+    mode = "external"
+  }
+
+  kustomize {
+    image "dex" {
+      new_tag = "2.30"
+    }
+
+    patch {
+      content = <<EOT
+- op: replace
+  path: /spec/replicas
+  value: 3
+EOT
+    }
+
+    patch {
+      path = "patches/dex.yml"
+
+      target {
+        kind = "Deployment"
+      }
+    }
+  }
+}
+
+component "argocd" {
+  namespace = "argocd"
+}
+`
+
+func TestReadFromFormats(t *testing.T) {
+	orig := sopsDecrypt
+	sopsDecrypt = func(filename string) ([]byte, error) {
+		return []byte(`{"domain": "example", "secret": "s3cr3t"}`), nil
+	}
+	t.Cleanup(func() { sopsDecrypt = orig })
+
+	dir := writeConfigFiles(t, map[string]string{
+		"karavel.hcl": convertHCL,
+		"karavel.json": `{
+  "version": "1970.1",
+  "external_secrets": {"store": "vault"},
+  "post_renderer": {"labels": {"labels": {"team": "platform"}}},
+  "component": {
+    "dex": {
+      "namespace": "dex",
+      "replicas": 2,
+      "ingress": {"host": "dex.${sops(\"dex.enc.yaml\", \"domain\")}.com", "tls": true},
+      "clientSecret": "${sops(\"dex.enc.yaml\", \"secret\")}",
+      "banner": "costs $${HOME} \"quoted\"",
+      "secrets": {"mode": "external"},
+      "kustomize": {
+        "image": {"dex": {"new_tag": "2.30"}},
+        "patch": [
+          {"content": "- op: replace\n  path: /spec/replicas\n  value: 3\n"},
+          {"path": "patches/dex.yml", "target": {"kind": "Deployment"}}
+        ]
+      }
+    },
+    "argocd": {"namespace": "argocd"}
+  }
+}
+`,
+		"karavel.yaml": `
+version: "1970.1"
+external_secrets:
+  store: vault
+post_renderer:
+  labels:
+    labels: {team: platform}
+component:
+  dex:
+    namespace: dex
+    replicas: 2
+    ingress:
+      host: dex.${sops("dex.enc.yaml", "domain")}.com
+      tls: true
+    clientSecret: ${sops("dex.enc.yaml", "secret")}
+    banner: costs $${HOME} "quoted"
+    secrets: {mode: external}
+    kustomize:
+      image:
+        dex: {new_tag: "2.30"}
+      patch:
+        - content: |
+            - op: replace
+              path: /spec/replicas
+              value: 3
+        - path: patches/dex.yml
+          target: {kind: Deployment}
+  argocd:
+    namespace: argocd
+`,
+	})
+
+	read := func(name string) *Config {
+		cfg, err := ReadFrom(ioutil.Discard, filepath.Join(dir, name))
+		require.NoError(t, err, name)
+		for i := range cfg.Components {
+			cfg.Components[i].Remain = nil
+			cfg.Components[i].RawParams = nil
+		}
+		return &cfg
+	}
+
+	hclCfg := read("karavel.hcl")
+	require.Len(t, hclCfg.Components, 2)
+	assert.JSONEq(t, `{"banner":"costs ${HOME} \"quoted\"","clientSecret":"s3cr3t","ingress":{"host":"dex.example.com","tls":true},"replicas":2}`, string(hclCfg.Components[0].JsonParams))
+
+	for _, name := range []string{"karavel.json", "karavel.yaml"} {
+		cfg := read(name)
+		assert.Equal(t, hclCfg.Version, cfg.Version, name)
+		assert.Equal(t, hclCfg.Components, cfg.Components, name)
+		assert.Equal(t, hclCfg.ExternalSecrets, cfg.ExternalSecrets, name)
+		assert.Equal(t, hclCfg.PostRenderers, cfg.PostRenderers, name)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	js, err := Convert([]byte(convertHCL), "karavel.hcl", FormatJSON)
+	require.NoError(t, err)
+	assert.Contains(t, string(js), `"clientSecret": "${sops(\"dex.enc.yaml\", \"secret\")}"`)
+	assert.Contains(t, string(js), `"host": "dex.${sops(\"dex.enc.yaml\", \"domain\")}.com"`)
+
+	yml, err := Convert(js, "karavel.json", FormatYAML)
+	require.NoError(t, err)
+
+	back, err := Convert(yml, "karavel.yaml", FormatHCL)
+	require.NoError(t, err)
+	assert.Equal(t, convertHCL, string(back))
+}
+
+func TestEditRejectsOtherFormats(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"karavel.json": `{"version": "1970.1"}`,
+	})
+
+	err := SetVersion(filepath.Join(dir, "karavel.json"), "1970.2")
+	assert.ErrorContains(t, err, "karavel config convert")
+}
@@ -0,0 +1,181 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// ComponentBlock describes a component block to be added to a config file
+type ComponentBlock struct {
+	Name          string
+	ComponentName string
+	Namespace     string
+	Version       string
+	// Params are written as commented out attributes, to be reviewed by the user
+	Params map[string]any
+}
+
+// SetVersion updates the version attribute in the given config file, preserving comments and formatting
+func SetVersion(filename string, version string) error {
+	return editFile(filename, func(f *hclwrite.File) error {
+		f.Body().SetAttributeValue("version", cty.StringVal(version))
+		return nil
+	})
+}
+
+// AddComponent appends a new component block at the end of the given config file
+func AddComponent(filename string, block ComponentBlock) error {
+	return editFile(filename, func(f *hclwrite.File) error {
+		if findComponentBlock(f, block.Name) != nil {
+			return fmt.Errorf("component '%s' is already declared in %s", block.Name, filename)
+		}
+
+		tmp := hclwrite.NewEmptyFile()
+		b := tmp.Body().AppendNewBlock("component", []string{block.Name}).Body()
+		if block.ComponentName != "" && block.ComponentName != block.Name {
+			b.SetAttributeValue("component", cty.StringVal(block.ComponentName))
+		}
+		if block.Namespace != "" {
+			b.SetAttributeValue("namespace", cty.StringVal(block.Namespace))
+		}
+		if block.Version != "" {
+			b.SetAttributeValue("version", cty.StringVal(block.Version))
+		}
+
+		if len(block.Params) > 0 {
+			comments, err := commentedParams(block.Params)
+			if err != nil {
+				return err
+			}
+
+			b.AppendNewline()
+			b.AppendUnstructuredTokens(comments)
+		}
+
+		body := f.Body()
+		if len(bytes.TrimSpace(f.Bytes())) > 0 {
+			body.AppendNewline()
+		}
+		body.AppendUnstructuredTokens(hclwrite.Tokens{{
+			Type:  hclsyntax.TokenNil,
+			Bytes: hclwrite.Format(tmp.Bytes()),
+		}})
+		return nil
+	})
+}
+
+// RemoveComponent removes the block of the named component from the given config file
+func RemoveComponent(filename string, name string) error {
+	return editFile(filename, func(f *hclwrite.File) error {
+		block := findComponentBlock(f, name)
+		if block == nil {
+			return fmt.Errorf("component '%s' is not declared in %s", name, filename)
+		}
+
+		f.Body().RemoveBlock(block)
+		return nil
+	})
+}
+
+func findComponentBlock(f *hclwrite.File, name string) *hclwrite.Block {
+	for _, b := range f.Body().Blocks() {
+		labels := b.Labels()
+		if b.Type() == "component" && len(labels) == 1 && strings.EqualFold(labels[0], name) {
+			return b
+		}
+	}
+	return nil
+}
+
+func editFile(filename string, edit func(f *hclwrite.File) error) error {
+	if f := FormatOf(filename); f != FormatHCL {
+		return fmt.Errorf("%s is written in %s and cannot be edited, convert it to HCL with 'karavel config convert' first", filename, f)
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	f, diags := hclwrite.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return fmt.Errorf("failed to parse config file %s: %s", filename, diags.Error())
+	}
+
+	if err := edit(f); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, f.Bytes(), info.Mode())
+}
+
+func commentedParams(params map[string]any) (hclwrite.Tokens, error) {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tmp := hclwrite.NewEmptyFile()
+	for _, k := range keys {
+		v, err := toCtyValue(params[k])
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert default value for param '%s': %w", k, err)
+		}
+		tmp.Body().SetAttributeValue(k, v)
+	}
+
+	var buf bytes.Buffer
+	for _, line := range strings.Split(strings.TrimRight(string(tmp.Bytes()), "\n"), "\n") {
+		buf.WriteString("# " + line + "\n")
+	}
+
+	return hclwrite.Tokens{{
+		Type:  hclsyntax.TokenComment,
+		Bytes: buf.Bytes(),
+	}}, nil
+}
+
+func toCtyValue(v any) (cty.Value, error) {
+	j, err := json.Marshal(v)
+	if err != nil {
+		return cty.NilVal, err
+	}
+
+	t, err := ctyjson.ImpliedType(j)
+	if err != nil {
+		return cty.NilVal, err
+	}
+
+	return ctyjson.Unmarshal(j, t)
+}
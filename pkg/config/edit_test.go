@@ -0,0 +1,108 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const editCfg = `# Platform release
+version = "2022.1" # keep me updated
+
+# GitOps
+component "argocd" {
+  namespace = "argocd"
+}
+`
+
+func prepareEditConfig(t *testing.T) string {
+	filename := filepath.Join(t.TempDir(), "karavel.hcl")
+	require.NoError(t, ioutil.WriteFile(filename, []byte(editCfg), 0o644))
+	return filename
+}
+
+func readFile(t *testing.T, filename string) string {
+	b, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestSetVersion(t *testing.T) {
+	filename := prepareEditConfig(t)
+
+	require.NoError(t, SetVersion(filename, "2022.2"))
+	assert.Equal(t, `# Platform release
+version = "2022.2" # keep me updated
+
+# GitOps
+component "argocd" {
+  namespace = "argocd"
+}
+`, readFile(t, filename))
+}
+
+func TestAddComponent(t *testing.T) {
+	filename := prepareEditConfig(t)
+
+	require.NoError(t, AddComponent(filename, ComponentBlock{
+		Name:          "sso",
+		ComponentName: "dex",
+		Namespace:     "dex",
+		Version:       "0.2.0",
+		Params: map[string]any{
+			"hostname": "dex.example.com",
+			"connectors": map[string]any{
+				"github": true,
+			},
+		},
+	}))
+
+	assert.Equal(t, editCfg+`
+component "sso" {
+  component = "dex"
+  namespace = "dex"
+  version   = "0.2.0"
+
+  # connectors = {
+  #   github = true
+  # }
+  # hostname = "dex.example.com"
+}
+`, readFile(t, filename))
+
+	cfg, err := ReadFrom(ioutil.Discard, filename)
+	require.NoError(t, err)
+	assert.Len(t, cfg.Components, 2)
+
+	assert.Error(t, AddComponent(filename, ComponentBlock{Name: "argocd"}))
+}
+
+func TestRemoveComponent(t *testing.T) {
+	filename := prepareEditConfig(t)
+
+	// comments leading the block are removed with it
+	require.NoError(t, RemoveComponent(filename, "ArgoCD"))
+	assert.Equal(t, `# Platform release
+version = "2022.1" # keep me updated
+
+`, readFile(t, filename))
+
+	assert.Error(t, RemoveComponent(filename, "argocd"))
+}
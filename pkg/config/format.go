@@ -0,0 +1,325 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the syntax a config file is written in
+type Format string
+
+const (
+	// FormatHCL is the native HCL syntax
+	FormatHCL Format = "hcl"
+	// FormatJSON is the JSON syntax of HCL
+	FormatJSON Format = "json"
+	// FormatYAML mirrors the JSON syntax of HCL in YAML
+	FormatYAML Format = "yaml"
+)
+
+// ParseFormat validates a format name
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatHCL, FormatJSON, FormatYAML:
+		return f, nil
+	case "yml":
+		return FormatYAML, nil
+	default:
+		return "", fmt.Errorf("unsupported config format '%s', must be one of %s, %s, %s", s, FormatHCL, FormatJSON, FormatYAML)
+	}
+}
+
+// FormatOf selects the format of a config file from its extension. Unknown extensions are read as HCL
+func FormatOf(filename string) Format {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	default:
+		return FormatHCL
+	}
+}
+
+// orderedMap is a JSON object that preserves the order of its keys, which is meaningful for HCL blocks
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{values: make(map[string]any)}
+}
+
+func (m *orderedMap) set(key string, v any) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = v
+}
+
+// decodeJSON decodes a JSON document into ordered maps, slices, json.Number, strings, booleans and nils
+func decodeJSON(src []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.UseNumber()
+
+	v, err := decodeJSONValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected content after the JSON document")
+	}
+	return v, nil
+}
+
+func decodeJSONValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			m := newOrderedMap()
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				v, err := decodeJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				m.set(key.(string), v)
+			}
+			_, err := dec.Token()
+			return m, err
+		case '[':
+			l := make([]any, 0)
+			for dec.More() {
+				v, err := decodeJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				l = append(l, v)
+			}
+			_, err := dec.Token()
+			return l, err
+		}
+		return nil, fmt.Errorf("unexpected delimiter %s", t)
+	default:
+		return t, nil
+	}
+}
+
+// decodeYAML decodes a YAML document into the same values as decodeJSON
+func decodeYAML(src []byte) (any, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		return newOrderedMap(), nil
+	}
+	return fromYAMLNode(&doc)
+}
+
+func fromYAMLNode(n *yaml.Node) (any, error) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		return fromYAMLNode(n.Content[0])
+	case yaml.AliasNode:
+		return fromYAMLNode(n.Alias)
+	case yaml.MappingNode:
+		m := newOrderedMap()
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: keys must be strings", k.Line)
+			}
+			val, err := fromYAMLNode(v)
+			if err != nil {
+				return nil, err
+			}
+			m.set(k.Value, val)
+		}
+		return m, nil
+	case yaml.SequenceNode:
+		l := make([]any, 0, len(n.Content))
+		for _, c := range n.Content {
+			v, err := fromYAMLNode(c)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, v)
+		}
+		return l, nil
+	default:
+		var v any
+		if err := n.Decode(&v); err != nil {
+			return nil, err
+		}
+		switch s := v.(type) {
+		case int, int64, uint64, float64:
+			b, err := json.Marshal(s)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n.Line, err)
+			}
+			return json.Number(b), nil
+		case string, bool, nil:
+			return s, nil
+		default:
+			return nil, fmt.Errorf("line %d: unsupported value %v", n.Line, v)
+		}
+	}
+}
+
+// encodeJSON encodes a value produced by decodeJSON, indenting it if indent is not empty
+func encodeJSON(v any, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, v, indent, ""); err != nil {
+		return nil, err
+	}
+	if indent != "" {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+func writeJSON(buf *bytes.Buffer, v any, indent string, prefix string) error {
+	newline := func(p string) {
+		if indent != "" {
+			buf.WriteByte('\n')
+			buf.WriteString(p)
+		}
+	}
+	sep := ":"
+	if indent != "" {
+		sep = ": "
+	}
+
+	switch t := v.(type) {
+	case *orderedMap:
+		if len(t.keys) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteByte('{')
+		for i, k := range t.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			newline(prefix + indent)
+			if err := writeJSONScalar(buf, k); err != nil {
+				return err
+			}
+			buf.WriteString(sep)
+			if err := writeJSON(buf, t.values[k], indent, prefix+indent); err != nil {
+				return err
+			}
+		}
+		newline(prefix)
+		buf.WriteByte('}')
+	case []any:
+		if len(t) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i, e := range t {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			newline(prefix + indent)
+			if err := writeJSON(buf, e, indent, prefix+indent); err != nil {
+				return err
+			}
+		}
+		newline(prefix)
+		buf.WriteByte(']')
+	default:
+		return writeJSONScalar(buf, t)
+	}
+	return nil
+}
+
+func writeJSONScalar(buf *bytes.Buffer, v any) error {
+	var sb bytes.Buffer
+	enc := json.NewEncoder(&sb)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	buf.Write(bytes.TrimSuffix(sb.Bytes(), []byte("\n")))
+	return nil
+}
+
+// encodeYAML encodes a value produced by decodeJSON as a YAML document
+func encodeYAML(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(toYAMLNode(v)); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func toYAMLNode(v any) *yaml.Node {
+	switch t := v.(type) {
+	case *orderedMap:
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, k := range t.keys {
+			n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}, toYAMLNode(t.values[k]))
+		}
+		return n
+	case []any:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, e := range t {
+			n.Content = append(n.Content, toYAMLNode(e))
+		}
+		return n
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(string(t), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: string(t)}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(t)}
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	default:
+		n := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fmt.Sprint(t)}
+		if strings.Contains(n.Value, "\n") {
+			n.Style = yaml.LiteralStyle
+		}
+		return n
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/tidwall/gjson"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// secretMark marks values read from encrypted files, so that they can be redacted from logs
+const secretMark = "karavel.io/secret"
+
+// sopsDecrypt decrypts a SOPS encrypted YAML or JSON file, returning its content as JSON
+var sopsDecrypt = func(filename string) ([]byte, error) {
+	sops, err := exec.LookPath("sops")
+	if err != nil {
+		return nil, fmt.Errorf("sops was not found in PATH, install it to decrypt %s", filename)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(sops, "--decrypt", "--output-type", "json", filename)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w: %s", filename, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// newEvalContext returns the context used to evaluate component params, exposing the following functions:
+//
+//	sops(file)       the content of a SOPS encrypted YAML or JSON file
+//	sops(file, key)  a single value from a SOPS encrypted file, selected with a dotted path such as "oidc.clientSecret"
+//
+// Relative file paths are resolved against basedir, and read is called once for every file decrypted.
+// Decrypted values are marked as secret.
+func newEvalContext(basedir string, read func(file string)) *hcl.EvalContext {
+	cache := make(map[string][]byte)
+	decrypt := func(file string) ([]byte, error) {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(basedir, file)
+		}
+
+		if data, ok := cache[file]; ok {
+			return data, nil
+		}
+
+		data, err := sopsDecrypt(file)
+		if err != nil {
+			return nil, err
+		}
+		cache[file] = data
+		read(file)
+		return data, nil
+	}
+
+	sops := function.New(&function.Spec{
+		Params: []function.Parameter{
+			{Name: "file", Type: cty.String},
+		},
+		VarParam: &function.Parameter{Name: "key", Type: cty.String},
+		Type:     function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			if len(args) > 2 {
+				return cty.NilVal, fmt.Errorf("sops takes at most 2 arguments, a file and a key")
+			}
+
+			file := args[0].AsString()
+			data, err := decrypt(file)
+			if err != nil {
+				return cty.NilVal, err
+			}
+
+			if len(args) == 2 {
+				key := args[1].AsString()
+				res := gjson.GetBytes(data, key)
+				if !res.Exists() {
+					return cty.NilVal, fmt.Errorf("key '%s' not found in %s", key, file)
+				}
+				data = []byte(res.Raw)
+			}
+
+			t, err := ctyjson.ImpliedType(data)
+			if err != nil {
+				return cty.NilVal, err
+			}
+
+			v, err := ctyjson.Unmarshal(data, t)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return v.Mark(secretMark), nil
+		},
+	})
+
+	return &hcl.EvalContext{
+		Functions: map[string]function.Function{
+			"sops": sops,
+		},
+	}
+}
+
+// secretPaths removes the secret marks from v, returning the JSON paths of the secret values
+func secretPaths(v cty.Value) (cty.Value, []string) {
+	unmarked, pvm := v.UnmarkDeepWithPaths()
+
+	var paths []string
+	for _, p := range pvm {
+		if _, ok := p.Marks[secretMark]; !ok {
+			continue
+		}
+
+		parts := make([]string, 0, len(p.Path))
+		for _, step := range p.Path {
+			switch s := step.(type) {
+			case cty.GetAttrStep:
+				parts = append(parts, escapePathKey(s.Name))
+			case cty.IndexStep:
+				if s.Key.Type() == cty.String {
+					parts = append(parts, escapePathKey(s.Key.AsString()))
+				} else {
+					parts = append(parts, s.Key.AsBigFloat().Text('f', 0))
+				}
+			}
+		}
+		paths = append(paths, strings.Join(parts, "."))
+	}
+
+	return unmarked, paths
+}
+
+var pathKeyReplacer = strings.NewReplacer(".", "\\.", "*", "\\*", "?", "\\?")
+
+func escapePathKey(key string) string {
+	return pathKeyReplacer.Replace(key)
+}
@@ -0,0 +1,76 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSopsFunction(t *testing.T) {
+	dir := t.TempDir()
+	var decrypted []string
+	orig := sopsDecrypt
+	sopsDecrypt = func(filename string) ([]byte, error) {
+		decrypted = append(decrypted, filename)
+		if filepath.Base(filename) != "dex.enc.yaml" {
+			return nil, errors.New("no such file")
+		}
+		return []byte(`{"oidc": {"clientSecret": "s3cr3t", "clientID": "karavel"}, "s3": {"keys": ["a", "b"]}}`), nil
+	}
+	t.Cleanup(func() { sopsDecrypt = orig })
+
+	cpath := filepath.Join(dir, "karavel.hcl")
+	require.NoError(t, ioutil.WriteFile(cpath, []byte(`
+version = "1970.1"
+
+component "dex" {
+  namespace = "dex"
+
+  clientSecret = sops("secrets/dex.enc.yaml", "oidc.clientSecret")
+  storage = {
+    accessKey = sops("secrets/dex.enc.yaml", "s3.keys.0")
+    bucket    = "dex"
+  }
+  url = "https://${sops("secrets/dex.enc.yaml", "oidc.clientID")}@example.com"
+}
+`), 0o644))
+
+	cfg, err := ReadFrom(ioutil.Discard, cpath)
+	require.NoError(t, err)
+
+	c := cfg.Components[0]
+	assert.JSONEq(t, `{"clientSecret": "s3cr3t", "storage": {"accessKey": "a", "bucket": "dex"}, "url": "https://karavel@example.com"}`, c.JsonParams)
+	assert.Equal(t, []string{"clientSecret", "storage.accessKey", "url"}, c.SecretParams)
+
+	// each file is decrypted once, relative to the config file
+	assert.Equal(t, []string{filepath.Join(dir, "secrets", "dex.enc.yaml")}, decrypted)
+
+	require.NoError(t, ioutil.WriteFile(cpath, []byte(`
+version = "1970.1"
+
+component "dex" {
+  clientSecret = sops("secrets/dex.enc.yaml", "oidc.missing")
+}
+`), 0o644))
+
+	_, err = ReadFrom(ioutil.Discard, cpath)
+	assert.ErrorIs(t, err, ErrConfigParseFailed)
+}
@@ -0,0 +1,312 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/karavel-io/cli/internal/helmw"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Include merges the config files matching Path into the config, as if they were part of the file declaring the include.
+// Path is relative to the declaring file and may be a glob pattern. Files already loaded are not loaded again.
+type Include struct {
+	Path string `hcl:"path"`
+}
+
+var (
+	declarationsSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "version"}, {Name: "stable_repo"}, {Name: "unstable_repo"}},
+		Blocks:     []hcl.BlockHeaderSchema{{Type: "component", LabelNames: []string{"name"}}},
+	}
+	componentDeclarationSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "component"}, {Name: "version"}},
+	}
+	includeSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "include"}},
+	}
+	versionSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "version"}},
+	}
+)
+
+// Declarations is the part of a config that can be read without evaluating the component params,
+// which may require the keys to decrypt secrets
+type Declarations struct {
+	Version             string
+	HelmStableRepoUrl   string
+	HelmUnstableRepoUrl string
+	Components          []DeclaredComponent
+	// MainFile is the config file declaring the platform version
+	MainFile string
+}
+
+// DeclaredComponent is a component block of the config. Attributes that are not literals are left empty.
+type DeclaredComponent struct {
+	Name          string
+	ComponentName string
+	Version       string
+	Unstable      bool
+	// File is the config file declaring the component
+	File string
+}
+
+// ReadDeclarations reads the platform version, repositories and components declared by a config,
+// without evaluating any expression
+func ReadDeclarations(logw io.Writer, filename string) (Declarations, error) {
+	var d Declarations
+
+	p := hclparse.NewParser()
+	w := hcl.NewDiagnosticTextWriter(logw, p.Files(), 79, true)
+	filenames, err := configFiles(filename)
+	if err != nil {
+		return d, err
+	}
+
+	files, diags := parseFiles(p, filenames)
+	if diags.HasErrors() {
+		_ = w.WriteDiagnostics(diags)
+		return d, ErrConfigParseFailed
+	}
+
+	literal := func(attrs hcl.Attributes, name string) string {
+		attr, ok := attrs[name]
+		if !ok {
+			return ""
+		}
+		v, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || v.IsNull() || !v.IsWhollyKnown() || !v.Type().Equals(cty.String) {
+			return ""
+		}
+		return v.AsString()
+	}
+
+	for _, f := range files {
+		content, _, _ := f.Body.PartialContent(declarationsSchema)
+		if v := literal(content.Attributes, "version"); v != "" {
+			d.Version = v
+		}
+		if v := literal(content.Attributes, "stable_repo"); v != "" {
+			d.HelmStableRepoUrl = v
+		}
+		if v := literal(content.Attributes, "unstable_repo"); v != "" {
+			d.HelmUnstableRepoUrl = v
+		}
+
+		for _, block := range content.Blocks {
+			attrs, _, _ := block.Body.PartialContent(componentDeclarationSchema)
+			dc := DeclaredComponent{
+				Name:          strings.ToLower(block.Labels[0]),
+				ComponentName: literal(attrs.Attributes, "component"),
+				Version:       literal(attrs.Attributes, "version"),
+				File:          block.DefRange.Filename,
+			}
+			if strings.HasPrefix(strings.ToLower(dc.Version), "unstable:") {
+				dc.Version = strings.SplitAfter(dc.Version, ":")[1]
+				dc.Unstable = true
+			}
+			d.Components = append(d.Components, dc)
+		}
+	}
+
+	d.MainFile = versionFile(files)
+	d.HelmStableRepoUrl = helmw.GetRepoUrl(d.Version, d.HelmStableRepoUrl)
+	d.HelmUnstableRepoUrl = helmw.GetRepoUrl("unstable", d.HelmUnstableRepoUrl)
+	return d, nil
+}
+
+// ProjectDir returns the directory of the project whose config is read from path, either a file or a directory
+func ProjectDir(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return path
+	}
+	return filepath.Dir(path)
+}
+
+// configFiles lists the files a config is read from: the file itself,
+// or the *.hcl files of a directory, merged like Terraform does
+func configFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(path, "*.hcl"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no config files found in directory %s", path)
+	}
+	return files, nil
+}
+
+// parseFiles parses the config files and the ones they include, in the order they are found
+func parseFiles(p *hclparse.Parser, filenames []string) ([]*hcl.File, hcl.Diagnostics) {
+	var files []*hcl.File
+	var diags hcl.Diagnostics
+	seen := make(map[string]bool)
+
+	queue := filenames
+	for len(queue) > 0 {
+		filename := queue[0]
+		queue = queue[1:]
+
+		abs, err := filepath.Abs(filename)
+		if err != nil {
+			abs = filename
+		}
+		if seen[abs] {
+			continue
+		}
+		seen[abs] = true
+
+		f, fdiags := parseFile(p, filename)
+		diags = append(diags, fdiags...)
+		if f == nil {
+			continue
+		}
+		files = append(files, f)
+
+		content, _, _ := f.Body.PartialContent(includeSchema)
+		for _, block := range content.Blocks {
+			var inc Include
+			if idiags := gohcl.DecodeBody(block.Body, nil, &inc); idiags.HasErrors() {
+				diags = append(diags, idiags...)
+				continue
+			}
+
+			pattern := inc.Path
+			if !filepath.IsAbs(pattern) {
+				pattern = filepath.Join(filepath.Dir(filename), pattern)
+			}
+
+			matches, err := filepath.Glob(pattern)
+			if err == nil && len(matches) == 0 {
+				err = fmt.Errorf("no files match %s", pattern)
+			}
+			if err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid include",
+					Detail:   fmt.Sprintf("Could not include %q: %s.", inc.Path, err),
+					Subject:  block.DefRange.Ptr(),
+				})
+				continue
+			}
+			queue = append(queue, matches...)
+		}
+	}
+
+	return files, diags
+}
+
+// parseFile parses a config file according to its format. YAML files are converted to the JSON syntax of HCL,
+// so diagnostics point at the lines of the converted document.
+func parseFile(p *hclparse.Parser, filename string) (*hcl.File, hcl.Diagnostics) {
+	switch FormatOf(filename) {
+	case FormatJSON:
+		return p.ParseJSONFile(filename)
+	case FormatYAML:
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to read file",
+				Detail:   fmt.Sprintf("The configuration file %q could not be read.", filename),
+			}}
+		}
+
+		v, err := decodeYAML(src)
+		var js []byte
+		if err == nil {
+			js, err = encodeJSON(v, "  ")
+		}
+		if err != nil {
+			return nil, hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid YAML",
+				Detail:   fmt.Sprintf("The configuration file %q is not valid YAML: %s.", filename, err),
+			}}
+		}
+		return p.ParseJSON(js, filename)
+	default:
+		return p.ParseHCLFile(filename)
+	}
+}
+
+// componentFiles maps each component name to the file declaring it.
+// Components declared more than once are reported, pointing at both declarations.
+func componentFiles(files []*hcl.File) (map[string]string, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	declared := make(map[string]*hcl.Block)
+	res := make(map[string]string)
+	for _, block := range findBlocks(files, hcl.BlockHeaderSchema{Type: "component", LabelNames: []string{"name"}}) {
+		name := strings.ToLower(block.Labels[0])
+		if prev, ok := declared[name]; ok {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate component",
+				Detail: fmt.Sprintf("Component %q was already declared at %s:%d. Each component must have a unique name.",
+					name, prev.DefRange.Filename, prev.DefRange.Start.Line),
+				Subject: block.DefRange.Ptr(),
+			})
+			continue
+		}
+
+		declared[name] = block
+		res[name] = block.DefRange.Filename
+	}
+	return res, diags
+}
+
+// findBlocks returns the top level blocks of the files matching header, in the order they are decoded from the merged files.
+// Malformed blocks are skipped, as they are reported when decoding the config.
+func findBlocks(files []*hcl.File, header hcl.BlockHeaderSchema) hcl.Blocks {
+	var blocks hcl.Blocks
+	schema := &hcl.BodySchema{Blocks: []hcl.BlockHeaderSchema{header}}
+	for _, f := range files {
+		content, _, _ := f.Body.PartialContent(schema)
+		blocks = append(blocks, content.Blocks...)
+	}
+	return blocks
+}
+
+// versionFile returns the file declaring the platform version, the first one if it is missing
+func versionFile(files []*hcl.File) string {
+	for _, f := range files {
+		content, _, _ := f.Body.PartialContent(versionSchema)
+		if attr, ok := content.Attributes["version"]; ok {
+			return attr.Range.Filename
+		}
+	}
+	if len(files) == 0 {
+		return ""
+	}
+	return files[0].Body.MissingItemRange().Filename
+}
@@ -0,0 +1,180 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, ioutil.WriteFile(p, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestReadFromDirectory(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"karavel.hcl": `
+version = "1970.1"
+
+include {
+  path = "shared/*.hcl"
+}
+
+include {
+  path = "shared/loki.yaml"
+}
+
+component "argocd" {
+  namespace = "argocd"
+}
+`,
+		"dex.hcl": `
+component "dex" {
+  namespace = "dex"
+  kustomize {
+    patch {
+      path = "patches/dex.yml"
+    }
+  }
+}
+`,
+		"shared/monitoring.hcl": `
+component "prometheus" {
+  kustomize {
+    patch {
+      path = "patches/prometheus.yml"
+    }
+  }
+}
+
+# including a file twice has no effect
+include {
+  path = "../dex.hcl"
+}
+`,
+		// JSON and YAML files are only read when included
+		"shared/loki.yaml": `
+component:
+  loki:
+    namespace: monitoring
+`,
+		"notes.txt":     "not a config file",
+		"secrets.yaml":  "sops:\n  mac: ENC[AES256_GCM,data:abc]",
+		"renovate.json": `{"extends": ["config:base"]}`,
+	})
+
+	cfg, err := ReadFrom(ioutil.Discard, dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, "1970.1", cfg.Version)
+	names := make([]string, 0, len(cfg.Components))
+	for _, cc := range cfg.Components {
+		names = append(names, cc.Name)
+	}
+	assert.Equal(t, []string{"dex", "argocd", "prometheus", "loki"}, names)
+
+	// relative paths are resolved against the file declaring them
+	assert.Equal(t, filepath.Join(dir, "patches/dex.yml"), cfg.Components[0].Kustomize.Patches[0].Path)
+	assert.Equal(t, filepath.Join(dir, "shared/patches/prometheus.yml"), cfg.Components[2].Kustomize.Patches[0].Path)
+
+	assert.Equal(t, []string{
+		filepath.Join(dir, "dex.hcl"),
+		filepath.Join(dir, "karavel.hcl"),
+		filepath.Join(dir, "shared/monitoring.hcl"),
+		filepath.Join(dir, "shared/loki.yaml"),
+	}, cfg.Sources())
+	assert.Equal(t, filepath.Join(dir, "karavel.hcl"), cfg.MainFile())
+	assert.Equal(t, filepath.Join(dir, "shared/monitoring.hcl"), cfg.ComponentFile("prometheus"))
+	assert.Equal(t, dir, ProjectDir(dir))
+	assert.Equal(t, dir, ProjectDir(cfg.MainFile()))
+}
+
+func TestReadFromDuplicateComponents(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"karavel.hcl": `
+version = "1970.1"
+
+component "dex" {}
+`,
+		"identity.hcl": `
+component "Dex" {}
+`,
+	})
+
+	var logs bytes.Buffer
+	_, err := ReadFrom(&logs, dir)
+	assert.ErrorIs(t, err, ErrConfigParseFailed)
+	assert.Contains(t, logs.String(), "Duplicate component")
+	assert.Contains(t, logs.String(), filepath.Join(dir, "identity.hcl")+":2")
+	assert.Contains(t, logs.String(), "karavel.hcl line 4")
+}
+
+func TestReadFromMissingInclude(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"karavel.hcl": `
+version = "1970.1"
+
+include {
+  path = "missing.hcl"
+}
+`,
+	})
+
+	var logs bytes.Buffer
+	_, err := ReadFrom(&logs, filepath.Join(dir, "karavel.hcl"))
+	assert.ErrorIs(t, err, ErrConfigParseFailed)
+	assert.Contains(t, logs.String(), "Invalid include")
+}
+
+func TestReadDeclarations(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"karavel.hcl": `
+version     = "1970.1"
+stable_repo = "https://charts.example.com"
+
+component "argocd" {
+  version = "unstable:0.3.0"
+}
+`,
+		"dex.hcl": `
+component "Dex" {
+  component    = "dex-oidc"
+  clientSecret = sops("secrets/dex.enc.yaml", "clientSecret")
+}
+`,
+	})
+
+	d, err := ReadDeclarations(ioutil.Discard, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "1970.1", d.Version)
+	assert.Equal(t, "https://charts.example.com", d.HelmStableRepoUrl)
+	assert.Equal(t, filepath.Join(dir, "karavel.hcl"), d.MainFile)
+	assert.ElementsMatch(t, []DeclaredComponent{
+		{Name: "argocd", Version: "0.3.0", Unstable: true, File: filepath.Join(dir, "karavel.hcl")},
+		{Name: "dex", ComponentName: "dex-oidc", File: filepath.Join(dir, "dex.hcl")},
+	}, d.Components)
+}
@@ -0,0 +1,76 @@
+// Copyright 2022 The Karavel Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	PostRendererExec      = "exec"
+	PostRendererLabels    = "labels"
+	PostRendererRegistry  = "registry"
+	PostRendererNamespace = "namespace"
+)
+
+// PostRenderer transforms the rendered documents before they are written.
+// Only the attributes relevant to its type can be set.
+type PostRenderer struct {
+	Type string `hcl:"type,label"`
+	// Command and Args configure the exec post-renderer. Relative command paths are resolved against the config file
+	Command string   `hcl:"command,optional"`
+	Args    []string `hcl:"args,optional"`
+	// Labels configures the labels post-renderer
+	Labels map[string]string `hcl:"labels,optional"`
+	// Rewrites maps registry hosts to their replacement for the registry post-renderer
+	Rewrites map[string]string `hcl:"rewrites,optional"`
+	// Namespace configures the namespace post-renderer, defaulting to the component namespace
+	Namespace string `hcl:"namespace,optional"`
+}
+
+// resolvePostRenderers validates the post-renderers, resolving relative exec commands against basedir
+func resolvePostRenderers(basedir string, prs []PostRenderer) error {
+	for i := range prs {
+		pr := &prs[i]
+		var missing string
+		switch pr.Type {
+		case PostRendererExec:
+			if pr.Command == "" {
+				missing = "command"
+			} else if !filepath.IsAbs(pr.Command) && strings.ContainsRune(filepath.ToSlash(pr.Command), '/') {
+				pr.Command = filepath.Join(basedir, pr.Command)
+			}
+		case PostRendererLabels:
+			if len(pr.Labels) == 0 {
+				missing = "labels"
+			}
+		case PostRendererRegistry:
+			if len(pr.Rewrites) == 0 {
+				missing = "rewrites"
+			}
+		case PostRendererNamespace:
+		default:
+			return fmt.Errorf("unsupported post-renderer '%s', must be one of %s, %s, %s, %s", pr.Type,
+				PostRendererExec, PostRendererLabels, PostRendererRegistry, PostRendererNamespace)
+		}
+
+		if missing != "" {
+			return fmt.Errorf("%s post-renderer requires '%s' to be set", pr.Type, missing)
+		}
+	}
+	return nil
+}